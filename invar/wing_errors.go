@@ -105,6 +105,9 @@ var (
 	ErrSetLifecycleTag     = errors.New("Failed set file lifecycle tag")
 	ErrInactiveAccount     = errors.New("Inactive status account")
 	ErrCaseException       = errors.New("Case exception")
+	ErrFileOverSize        = errors.New("File size over")
+	ErrInvalidSignature    = errors.New("Invalid signature")
+	ErrReplayRequest       = errors.New("Replay request")
 )
 
 var (
@@ -186,6 +189,9 @@ var (
 	WErrSetLifecycleTag     = &WingErr{0x104B, ErrSetLifecycleTag}
 	WErrInactiveAccount     = &WingErr{0x104C, ErrInactiveAccount}
 	WErrCaseException       = &WingErr{0x104D, ErrCaseException}
+	WErrFileOverSize        = &WingErr{0x104E, ErrFileOverSize}
+	WErrInvalidSignature    = &WingErr{0x104F, ErrInvalidSignature}
+	WErrReplayRequest       = &WingErr{0x1050, ErrReplayRequest}
 )
 
 // Equal tow error if message same on char case
@@ -14,6 +14,7 @@ package invar
 import (
 	"container/list"
 	"strings"
+	"sync"
 )
 
 // mime types for browser download header 'Content-Type'
@@ -21,6 +22,7 @@ var (
 	mimeTypes       = make(map[string]string)
 	webMimeTypes    = make(map[string]string)
 	allowedDomains  = list.New()
+	domainsLock     sync.RWMutex
 	enableMimeType  = false
 	defaultMimeType = "application/octet-stream"
 )
@@ -143,8 +145,11 @@ func AddWebMimeType(format string) {
 	webMimeTypes[format] = mimeTypes[format]
 }
 
-// PushDomain push allowed domain on list back
+// PushDomain push allowed domain on list back, the domain support wildcard
+// subdomains as 'https://*.wengold.net', see MatchDomain().
 func PushDomain(origin string) {
+	domainsLock.Lock()
+	defer domainsLock.Unlock()
 	for e := allowedDomains.Front(); e != nil; e = e.Next() {
 		if e.Value.(string) == origin {
 			return
@@ -155,18 +160,101 @@ func PushDomain(origin string) {
 
 // ViaDomain verify whether referer is allowed
 func ViaDomain(referer string) bool {
+	domainsLock.RLock()
+	defer domainsLock.RUnlock()
 	if allowedDomains.Len() == 0 {
 		return true
 	}
 	for e := allowedDomains.Front(); e != nil; e = e.Next() {
-		if strings.HasPrefix(referer, e.Value.(string)) {
+		if MatchDomain(e.Value.(string), referer) {
 			return true
 		}
 	}
 	return false
 }
 
+// MatchDomain check whether the referer or origin match domain, the domain
+// support patterns as :
+//
+//   - '*' match any referers
+//   - 'https://*.wengold.net' match any subdomains of wengold.net on https
+//   - '*.wengold.net' match any subdomains of wengold.net on any scheme
+//   - 'https://wengold.net' match the domain and the paths of it
+//
+// The port must same as domain, and it default 80 of http, 443 of https when
+// absent, so 'https://wengold.net' not match 'https://wengold.net:8080'.
+func MatchDomain(domain, referer string) bool {
+	if domain == "*" {
+		return true
+	}
+
+	dscheme, dhost, dport, dpath := splitOrigin(domain)
+	rscheme, rhost, rport, rpath := splitOrigin(referer)
+	if dscheme != "" && dscheme != rscheme {
+		return false
+	}
+
+	// check port by default port of scheme when absent
+	if dport == "" {
+		dport = defaultPort(rscheme)
+	}
+	if rport == "" {
+		rport = defaultPort(rscheme)
+	}
+	if dport != rport {
+		return false
+	}
+
+	if suffix, ok := strings.CutPrefix(dhost, "*"); ok {
+		if !strings.HasPrefix(suffix, ".") || len(rhost) <= len(suffix) || !strings.HasSuffix(rhost, suffix) {
+			return false
+		}
+	} else if dhost != rhost {
+		return false
+	}
+
+	// check the paths of referer when domain has path
+	if dpath == "" || dpath == "/" {
+		return true
+	} else if !strings.HasPrefix(rpath, dpath) {
+		return false
+	} else if len(rpath) == len(dpath) || strings.HasSuffix(dpath, "/") {
+		return true
+	}
+	return strings.IndexByte("/?#", rpath[len(dpath)]) >= 0
+}
+
 // ViaWebContent verfiy whether ext is support web content
 func ViaWebContent(ext string) bool {
 	return webMimeTypes[strings.TrimLeft(ext, ".")] != ""
 }
+
+// splitOrigin split url or origin to lower case scheme and host, port and paths.
+func splitOrigin(url string) (string, string, string, string) {
+	scheme := ""
+	if i := strings.Index(url, "://"); i >= 0 {
+		scheme, url = strings.ToLower(url[:i]), url[i+3:]
+	}
+
+	path := ""
+	if i := strings.IndexAny(url, "/?#"); i >= 0 {
+		url, path = url[:i], url[i:]
+	}
+
+	port := ""
+	if i := strings.LastIndexByte(url, ':'); i >= 0 && !strings.Contains(url[i:], "]") {
+		url, port = url[:i], url[i+1:]
+	}
+	return scheme, strings.ToLower(url), port, path
+}
+
+// defaultPort return the default port of scheme, or empty for unknown scheme.
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http", "ws":
+		return "80"
+	case "https", "wss":
+		return "443"
+	}
+	return ""
+}
@@ -26,8 +26,12 @@ const (
 	E409Duplicate        = http.StatusConflict
 	E410Gone             = http.StatusGone
 	E412InvalidState     = http.StatusPreconditionFailed
+	E413TooLarge         = http.StatusRequestEntityTooLarge
+	E415UnsupportedMedia = http.StatusUnsupportedMediaType
 	E423Locked           = http.StatusLocked
 	E426UpgradeRequired  = http.StatusUpgradeRequired
+	E429TooManyRequests  = http.StatusTooManyRequests
+	E500InternalError    = http.StatusInternalServerError
 )
 
 var statusText = map[int]string{
@@ -43,8 +47,12 @@ var statusText = map[int]string{
 	E409Duplicate:        "Duplicate Request",
 	E410Gone:             "Gone",
 	E412InvalidState:     "Invalid State",
+	E413TooLarge:         "Request Entity Too Large",
+	E415UnsupportedMedia: "Unsupported Media Type",
 	E423Locked:           "Resource Locked",
 	E426UpgradeRequired:  "Upgrade Header Required",
+	E429TooManyRequests:  "Too Many Requests",
+	E500InternalError:    "Internal Server Error",
 }
 
 // StatusText returns a text for the HTTP status code. It returns the empty
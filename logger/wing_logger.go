@@ -167,3 +167,9 @@ func I(v ...any) {
 func D(v ...any) {
 	logs.Debug(logFormatString(len(v)), v...)
 }
+
+// Flush flush all cached asynchronous logs into outputs, call it before
+// server exit to avoid losing logs.
+func Flush() {
+	beego.BeeLogger.Flush()
+}
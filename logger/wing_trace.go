@@ -0,0 +1,101 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package logger
+
+import (
+	"context"
+
+	"github.com/astaxie/beego/logs"
+)
+
+// TraceLogger the logger to output logs with request id, the request id
+// append after caller function name as:
+// -----------------------------------------------------------------------
+// 2023/05/31 10:56:36.609 [I] [code_file.go:89] FuncName() [request-id] Log messages
+// -----------------------------------------------------------------------
+//
+// `USAGE` :
+//
+//	// output logs with request id of context
+//	logger.Trace(logger.TraceOf(ctx)).I("Updated profile of", uuid)
+type TraceLogger struct {
+	id string // request id, output as normal logs when empty
+}
+
+// traceKey the context key of request id.
+type traceKey struct{}
+
+// Trace return the logger to output logs with the given request id.
+func Trace(id string) *TraceLogger {
+	return &TraceLogger{id: id}
+}
+
+// WithTrace return a copy of context carry the request id, the request id
+// can be get back by TraceOf() in the context or its children contexts.
+func WithTrace(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceKey{}, id)
+}
+
+// TraceOf return the request id carried by context, or empty string.
+func TraceOf(ctx context.Context) string {
+	if ctx != nil {
+		if id, ok := ctx.Value(traceKey{}).(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// ID return the request id of trace logger.
+func (t *TraceLogger) ID() string {
+	if t == nil {
+		return ""
+	}
+	return t.id
+}
+
+// E logs a message with request id at error level.
+func (t *TraceLogger) E(v ...any) {
+	args := traceArgs(t.ID(), v)
+	logs.Error(logFormatString(len(args)), args...)
+}
+
+// W logs a message with request id at warning level.
+func (t *TraceLogger) W(v ...any) {
+	args := traceArgs(t.ID(), v)
+	logs.Warn(logFormatString(len(args)), args...)
+}
+
+// I logs a message with request id at info level.
+func (t *TraceLogger) I(v ...any) {
+	args := traceArgs(t.ID(), v)
+	logs.Info(logFormatString(len(args)), args...)
+}
+
+// D logs a message with request id at debug level.
+func (t *TraceLogger) D(v ...any) {
+	args := traceArgs(t.ID(), v)
+	logs.Debug(logFormatString(len(args)), args...)
+}
+
+// ----------------
+
+// traceArgs insert request id before log args, the request id not put into
+// format string to avoid its '%' chars parsed as format verbs.
+func traceArgs(id string, v []any) []any {
+	if id == "" {
+		return v
+	}
+	return append([]any{"[" + id + "]"}, v...)
+}
@@ -11,6 +11,7 @@
 package mqtt
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -24,6 +25,7 @@ import (
 	"github.com/wengoldx/wcore/invar"
 	"github.com/wengoldx/wcore/logger"
 	"github.com/wengoldx/wcore/secure"
+	"github.com/wengoldx/wcore/utils"
 )
 
 // MQTT stub to manager MQTT connection.
@@ -179,6 +181,22 @@ func (stub *MqttStub) Connect(opt *mq.ClientOptions) error {
 		logger.E("Connect mqtt client, err:", token.Error())
 		return token.Error()
 	}
+
+	utils.RegisterHealthCheck("mqtt", stub.HealthCheck)
+	utils.OnShutdown(utils.ShutdownClient, "mqtt", func(ctx context.Context) error {
+		if stub.Client != nil && stub.Client.IsConnected() {
+			stub.Client.Disconnect(250) // wait 250ms to finish existing works
+		}
+		return nil
+	})
+	return nil
+}
+
+// HealthCheck check mqtt client whether connected with broker.
+func (stub *MqttStub) HealthCheck(ctx context.Context) error {
+	if stub.Client == nil || !stub.Client.IsConnected() {
+		return invar.ErrClientOffline
+	}
 	return nil
 }
 
@@ -197,6 +215,21 @@ func (stub *MqttStub) Publish(topic string, data any, Qos ...byte) error {
 // Notice that the data will encode as json bytes array if value type is Struct,
 // Pointer or map, or instead nil data to empty bytes array.
 func (stub *MqttStub) PublishOptions(topic string, data any, remain bool, Qos ...byte) error {
+	return stub.publish("", topic, data, remain, Qos...)
+}
+
+// PublishContext publish topic message same as Publish(), and embed the request
+// id carried by context into the json object, see PayloadRequestID.
+//
+// ---
+//
+//	stub.PublishContext(c.Ctx.Request.Context(), topic, data)
+func (stub *MqttStub) PublishContext(ctx context.Context, topic string, data any, Qos ...byte) error {
+	return stub.publish(logger.TraceOf(ctx), topic, data, stub.remain, Qos...)
+}
+
+// publish topic message, and embed the request id into json object if not empty.
+func (stub *MqttStub) publish(rid, topic string, data any, remain bool, Qos ...byte) error {
 	if stub.Client == nil {
 		logger.E("Abort publish topic:", topic, "on nil client!!")
 		return invar.ErrInvalidClient
@@ -209,7 +242,7 @@ func (stub *MqttStub) PublishOptions(topic string, data any, remain bool, Qos ..
 			if buffer, err := json.Marshal(data); err != nil {
 				return err
 			} else {
-				payload = buffer
+				payload = embedRequestID(rid, buffer)
 			}
 		default:
 			payload = data
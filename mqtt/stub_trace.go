@@ -0,0 +1,79 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PayloadRequestID the json field name of request id embedded in publish payloads,
+// set it empty to disable embed request id.
+var PayloadRequestID = "request_id"
+
+// RequestIDOf return the request id embedded in json payload of received message.
+//
+// `USAGE` :
+//
+//	stub.Subscribe(topic, func(c mq.Client, msg mq.Message) {
+//		tracer := logger.Trace(mqtt.RequestIDOf(msg.Payload()))
+//		tracer.I("Received topic:", msg.Topic())
+//		// handle message ...
+//	})
+func RequestIDOf(payload []byte) string {
+	if PayloadRequestID == "" || len(payload) == 0 || payload[0] != '{' {
+		return ""
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return ""
+	}
+
+	rid := ""
+	if raw, ok := fields[PayloadRequestID]; ok {
+		json.Unmarshal(raw, &rid)
+	}
+	return rid
+}
+
+// embedRequestID embed the request id into json object payload, it return the
+// origin payload when request id empty or payload already contain request id field.
+func embedRequestID(rid string, payload []byte) []byte {
+	if rid == "" || PayloadRequestID == "" {
+		return payload
+	}
+
+	data := bytes.TrimSpace(payload)
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return payload // not json object
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return payload // invalid json object
+	} else if _, ok := fields[PayloadRequestID]; ok {
+		return payload // already contain request id field
+	}
+
+	key, _ := json.Marshal(PayloadRequestID)
+
+	value, _ := json.Marshal(rid)
+	out := make([]byte, 0, len(data)+len(key)+len(value)+2)
+	out = append(out, '{')
+	out = append(out, key...)
+	out = append(out, ':')
+	out = append(out, value...)
+	if body := bytes.TrimSpace(data[1:]); len(body) > 1 {
+		out = append(out, ',') // not empty object
+	}
+	return append(out, data[1:]...)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	bctx "github.com/astaxie/beego/context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
+)
+
+// accessRecord the access infos of one request, it fill by beego filters and
+// controllers, then output by access log middleware after request finished.
+type accessRecord struct {
+	route string // router pattern of request
+	uuid  string // authed account uuid
+}
+
+// accessWriter the response writer to count response status and bytes.
+type accessWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// accessKey the request context key of access record.
+type accessKey struct{}
+
+// The route label of requests not matched any routers.
+const unmatchedRoute = "unmatched"
+
+var (
+	// MetricsRegistry the prometheus registry of http metrics, the go runtime
+	// and process collectors registered by default, and you can register
+	// custom collectors into it.
+	MetricsRegistry = prometheus.NewRegistry()
+
+	// AccessLogHidden hide the access logs, but still collect metrics.
+	AccessLogHidden = false
+
+	// metricsRoute the route of prometheus metrics, it will not logged.
+	metricsRoute = ""
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total", Help: "Total http requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "http_request_duration_seconds", Help: "Latency of http requests by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_response_bytes_total", Help: "Total response body bytes by method and route.",
+	}, []string{"method", "route"})
+)
+
+func init() {
+	MetricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpLatency, httpBytes,
+	)
+}
+
+// UseAccessLog output access log of each request after finished, and collect
+// latency, status and bytes metrics of routers into MetricsRegistry, then
+// expose them on the given route, or the route config in app.conf file, or
+// the default '/metrics' route. The access log format as:
+//
+//	[ACCESS] GET /v3/acc/profile 200 12.3ms 512B uuid:xxx ip:x.x.x.x rid:xxx
+//
+// ---
+//
+// `app.conf`
+//
+//	; Route of prometheus metrics, default /metrics
+//	metricsroute = /metrics
+//
+// `main.go`
+//
+//	// call it before utils.HttpServer()
+//	mvc.UseAccessLog()
+//	utils.HttpServer()
+func UseAccessLog(route ...string) {
+	metricsRoute = beego.AppConfig.DefaultString("metricsroute", "/metrics")
+	if len(route) > 0 && route[0] != "" {
+		metricsRoute = route[0]
+	}
+
+	beego.Handler(metricsRoute, promhttp.HandlerFor(MetricsRegistry, promhttp.HandlerOpts{}))
+	beego.InsertFilter("*", beego.BeforeExec, func(ctx *bctx.Context) {
+		if rec := accessRecordOf(ctx); rec != nil {
+			rec.route, _ = ctx.Input.GetData("RouterPattern").(string)
+		}
+	}, false)
+	utils.UseMiddleWares(AccessLogMiddleWare)
+	logger.I("Expose metrics on:", metricsRoute)
+}
+
+// AccessLogMiddleWare the http middleware to output access log and collect
+// metrics, it called by UseAccessLog(), or use it by custom http server.
+func AccessLogMiddleWare(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metricsRoute {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start, rec := time.Now(), &accessRecord{}
+		aw := &accessWriter{ResponseWriter: w}
+		next.ServeHTTP(aw, r.WithContext(context.WithValue(r.Context(), accessKey{}, rec)))
+		latency := time.Since(start)
+
+		status, route := aw.status, rec.route
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		state := strconv.Itoa(status)
+		httpRequests.WithLabelValues(r.Method, route, state).Inc()
+		httpLatency.WithLabelValues(r.Method, route).Observe(latency.Seconds())
+		httpBytes.WithLabelValues(r.Method, route).Add(float64(aw.bytes))
+
+		if !AccessLogHidden {
+			logger.I(fmt.Sprintf("[ACCESS] %s %s %d %v %dB uuid:%s ip:%s rid:%s", r.Method, route,
+				status, latency, aw.bytes, rec.uuid, clientIP(r), w.Header().Get(HeaderRequestID)))
+		}
+	})
+}
+
+// ----------------
+
+// accessRecordOf return the access record of request, or nil when access log unused.
+func accessRecordOf(ctx *bctx.Context) *accessRecord {
+	rec, _ := ctx.Request.Context().Value(accessKey{}).(*accessRecord)
+	return rec
+}
+
+// setAccessUuid set authed account uuid into access record.
+func setAccessUuid(ctx *bctx.Context, uuid string) {
+	if rec := accessRecordOf(ctx); rec != nil {
+		rec.uuid = uuid
+	}
+}
+
+// clientIP return client ip from X-Forwarded-For, X-Real-IP headers or remote address.
+func clientIP(r *http.Request) string {
+	if ips := r.Header.Get("X-Forwarded-For"); ips != "" {
+		return strings.TrimSpace(strings.Split(ips, ",")[0])
+	} else if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return ip
+	}
+	return r.RemoteAddr
+}
+
+// WriteHeader record response status.
+func (w *accessWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write count response bytes.
+func (w *accessWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implement http.Flusher for streaming responses.
+func (w *accessWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implement http.Hijacker for websocket connections.
+func (w *accessWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		if w.status == 0 {
+			w.status = http.StatusSwitchingProtocols
+		}
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("webserver doesn't support hijacking")
+}
+
+// CloseNotify implement http.CloseNotifier for long polling connections.
+func (w *accessWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ package mvc
 import (
 	"encoding/json"
 	"encoding/xml"
+	"reflect"
 	"strings"
 
 	"github.com/astaxie/beego"
@@ -85,6 +86,27 @@ func ensureValidatorGenerated() {
 	}
 }
 
+// UseJSONFieldNames use json, form or query tag names as the field names of
+// validate errors and translated messages, instead of struct field names, call
+// it before utils.HttpServer() for the validator cache struct fields.
+func UseJSONFieldNames() {
+	ensureValidatorGenerated()
+	Validator.RegisterTagNameFunc(validateFieldName)
+}
+
+// validateFieldName return the field name of validate errors by json, form or
+// query tags, and fallback to struct field name.
+func validateFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", bindForm, bindQuery} {
+		if name := strings.Split(field.Tag.Get(key), ",")[0]; name == "-" {
+			return field.Name
+		} else if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
 // RegisterValidators register struct field validators from given map
 func RegisterValidators(valmap map[string]validator.Func) {
 	for tag, valfunc := range valmap {
@@ -92,13 +114,18 @@ func RegisterValidators(valmap map[string]validator.Func) {
 	}
 }
 
-// RegisterFieldValidator register validators on struct field level
-func RegisterFieldValidator(tag string, valfunc validator.Func) {
+// RegisterFieldValidator register validators on struct field level, and the
+// optional error message translations, see RegisterValidatorTrans().
+func RegisterFieldValidator(tag string, valfunc validator.Func, trans ...map[string]string) {
 	ensureValidatorGenerated()
 	if err := Validator.RegisterValidation(tag, valfunc); err != nil {
 		logger.E("Register validator:"+tag+", err:", err)
 		return
 	}
+
+	if len(trans) > 0 && trans[0] != nil {
+		RegisterValidatorTrans(tag, trans[0])
+	}
 	logger.I("Registered validator:", tag)
 }
 
@@ -187,7 +214,7 @@ func (c *WingController) ResponData(state int, data ...map[any]any) {
 	}
 
 	ctl, act := c.GetControllerAndAction()
-	logger.I("Respone OK-DATA >", ctl+"."+act)
+	c.Logger().I("Respone OK-DATA >", ctl+"."+act)
 
 	c.Ctx.Output.Status = state
 	if len(data) > 0 {
@@ -200,7 +227,7 @@ func (c *WingController) ResponData(state int, data ...map[any]any) {
 func (c *WingController) ResponOK(hidelog ...bool) {
 	if !(len(hidelog) > 0 && hidelog[0]) {
 		ctl, act := c.GetControllerAndAction()
-		logger.I("Respone OK >", ctl+"."+act)
+		c.Logger().I("Respone OK >", ctl+"."+act)
 	}
 
 	w := c.Ctx.ResponseWriter
@@ -213,27 +240,17 @@ func (c *WingController) ResponOK(hidelog ...bool) {
 // ResponExErr sends a extend error as response data on 202 status code
 func (c *WingController) ResponExErr(errmsg invar.WExErr) {
 	ctl, act := c.GetControllerAndAction()
-	logger.E("Respone ERR-EX >", ctl+"."+act, "err:", errmsg.Message)
+	c.Logger().E("Respone ERR-EX >", ctl+"."+act, "err:", errmsg.Message)
 
 	c.Ctx.Output.Status = invar.StatusExError
 	c.Data["json"] = errmsg
 	c.ServeJSON()
 }
 
-// ErrorState response error state to client
+// ErrorState response error state to client, it will response structured
+// error body when ErrorBodyEnabled is true, see ErrorBody.
 func (c *WingController) ErrorState(state int, err ...string) {
-	ctl, act := c.GetControllerAndAction()
-	errmsg := invar.StatusText(state)
-	if len(err) > 0 {
-		errmsg += ", " + err[0]
-	}
-	logger.E("Respone ERR:", state, ">", ctl+"."+act, errmsg)
-
-	w := c.Ctx.ResponseWriter
-	w.WriteHeader(state)
-	// FIXME here maybe not set content type when response error
-	// w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(""))
+	c.responErrorBody(c.newErrorBody(state, err...))
 }
 
 // E400Params response 400 invalid params error state to client
@@ -249,7 +266,7 @@ func (c *WingController) E400Unmarshal(err ...string) {
 // E400Validate response 400 invalid params error state to client, then print
 // the params data and validate error
 func (c *WingController) E400Validate(ps any, err ...string) {
-	logger.E("Invalid input params:", ps)
+	c.Logger().E("Invalid input params:", ps)
 	c.ErrorState(invar.E400ParseParams, err...)
 }
 
@@ -288,11 +305,21 @@ func (c *WingController) E410Gone(err ...string) {
 	c.ErrorState(invar.E410Gone, err...)
 }
 
+// E413TooLarge response 413 request entity too large error state to client
+func (c *WingController) E413TooLarge(err ...string) {
+	c.ErrorState(invar.E413TooLarge, err...)
+}
+
 // E426UpgradeRequired response 426 upgrade required error state to client
 func (c *WingController) E426UpgradeRequired(err ...string) {
 	c.ErrorState(invar.E426UpgradeRequired, err...)
 }
 
+// E429TooMany response 429 too many requests error state to client
+func (c *WingController) E429TooMany(err ...string) {
+	c.ErrorState(invar.E429TooManyRequests, err...)
+}
+
 // ClientFrom return client ip from who requested
 func (c *WingController) ClientFrom() string {
 	return c.Ctx.Request.RemoteAddr
@@ -301,7 +328,7 @@ func (c *WingController) ClientFrom() string {
 // BindValue bind value with key from url, the dest container must pointer
 func (c *WingController) BindValue(key string, dest any) error {
 	if err := c.Ctx.Input.Bind(dest, key); err != nil {
-		logger.E("Parse", key, "from url, err:", err)
+		c.Logger().E("Parse", key, "from url, err:", err)
 		return invar.ErrInvalidData
 	}
 	return nil
@@ -338,7 +365,8 @@ func (c *WingController) DoAfterUnmarshalXml(ps any, nextFunc NextFunc, fs ...bo
 // ----------------
 
 // responCheckState check respon state and print out log, the datatype must
-// range in ['json', 'jsonp', 'xml', 'yaml'], if out of range current controller
+// range in ['json', 'jsonp', 'xml', 'yaml'], and it may change to 'protobuf'
+// or 'msgpack' by Accept header, if out of range current controller
 // just return blank string to close http connection.
 // the protect param set true by default, by can be change from input flags.
 func (c *WingController) responCheckState(datatype string, protect, hidelog bool, state int, data ...any) {
@@ -358,20 +386,33 @@ func (c *WingController) responCheckState(datatype string, protect, hidelog bool
 		 */
 		errmsg := invar.StatusText(state)
 		ctl, act := c.GetControllerAndAction()
-		logger.E("["+dt+"] Respone ERR:", state, ">", ctl+"."+act, errmsg)
+		c.Logger().E("["+dt+"] Respone ERR:", state, ">", ctl+"."+act, errmsg)
 	}
 
 	// Output simple ok response usually, but can hide by input flag.
 	if !hidelog {
 		ctl, act := c.GetControllerAndAction()
-		logger.I("["+dt+"] Respone OK >", ctl+"."+act)
+		c.Logger().I("["+dt+"] Respone OK >", ctl+"."+act)
 	}
 
+	// negotiate protobuf or msgpack output by Accept header
+	datatype = c.responseType(datatype, data...)
 	c.Ctx.Output.Status = state
 	if len(data) > 0 {
 		c.Data[datatype] = data[0]
 	}
 
+	// serve with cache headers, or 304 when client cache not modified
+	if state == invar.StatusOK && c.serveCached(datatype) {
+		return
+	}
+
+	// compress response body by Accept-Encoding header
+	if compressEnabled && datatype != "jsonp" && c.Ctx.Input.Header("Accept-Encoding") != "" {
+		c.serveBody(datatype)
+		return
+	}
+
 	switch datatype {
 	case "json":
 		c.ServeJSON()
@@ -381,6 +422,8 @@ func (c *WingController) responCheckState(datatype string, protect, hidelog bool
 		c.ServeXML()
 	case "yaml":
 		c.ServeYAML()
+	case dataProtobuf, dataMsgpack:
+		c.serveBody(datatype)
 	default:
 		// just return blank string to close http connection
 		logger.W("Invalid response data tyep:" + datatype)
@@ -397,18 +440,14 @@ func (c *WingController) doAfterValidatedInner(datatype string, ps any, nextFunc
 	}
 
 	// execute business function after unmarshal and validated
-	if status, resp := nextFunc(); resp != nil {
-		c.responCheckState(datatype, protect, hidelog, status, resp)
-	} else {
-		c.responCheckState(datatype, protect, hidelog, status)
-	}
+	c.responNext(outputType(datatype), protect, hidelog, nextFunc)
 }
 
 // validatrParams do bussiness action after success unmarshal params or validate the unmarshaled json data.
 //	@Return 400: Invalid input params(Unmarshal error or invalid params value).
-//	@Return 404: Internale server error(not support content type unless json and xml).
+//	@Return 404: Internale server error(not support content type unless json, xml, protobuf and msgpack).
 func (c *WingController) validatrParams(datatype string, ps any, validate bool) bool {
-	switch datatype {
+	switch datatype = c.requestType(datatype); datatype {
 	case "json":
 		if err := json.Unmarshal(c.Ctx.Input.RequestBody, ps); err != nil {
 			c.E400Unmarshal(err.Error())
@@ -419,6 +458,16 @@ func (c *WingController) validatrParams(datatype string, ps any, validate bool)
 			c.E400Unmarshal(err.Error())
 			return false
 		}
+	case dataProtobuf, dataMsgpack:
+		if err := unmarshalBinary(datatype, c.Ctx.Input.RequestBody, ps); err != nil {
+			c.E400Unmarshal(err.Error())
+			return false
+		}
+	case bindQuery, bindForm:
+		if err := c.bindParams(datatype, ps); err != nil {
+			c.E400Params(err.Error())
+			return false
+		}
 	default: // current not support the jsonp and yaml parse
 		c.E404Exception("Invalid data type:" + datatype)
 		return false
@@ -428,7 +477,7 @@ func (c *WingController) validatrParams(datatype string, ps any, validate bool)
 	if validate {
 		ensureValidatorGenerated()
 		if err := Validator.Struct(ps); err != nil {
-			c.E400Validate(ps, err.Error())
+			c.E400ValidateErr(ps, err)
 			return false
 		}
 	}
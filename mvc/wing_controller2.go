@@ -11,9 +11,7 @@
 package mvc
 
 import (
-	"strings"
-
-	"github.com/wengoldx/wcore/logger"
+	"context"
 )
 
 // WAuthController the extend controller base on WingController to support
@@ -27,6 +25,9 @@ import (
 //
 // * Location : Optional value of client indicator, global location
 //
+// The above headers is the default legacy auth scheme, it also support standard
+// 'Authorization: Bearer <jwt>' and api key schemes, see RegisterAuthSchemes().
+//
 // `USAGE` :
 //
 // The validator register code of input params struct see WingController description,
@@ -123,16 +124,37 @@ type AuthHandlerFunc func(token string) (string, string)
 // RoleHandlerFunc verify role access permission from account service.
 type RoleHandlerFunc func(sub, obj, act string) bool
 
-// Global handler function to auth token from http header
+// AuthContextFunc auth request token as AuthHandlerFunc, the context carry
+// request id of current request for tracing, see logger.TraceOf().
+type AuthContextFunc func(ctx context.Context, token string) (string, string)
+
+// RoleContextFunc verify role access permission as RoleHandlerFunc, the context
+// carry request id of current request for tracing, see logger.TraceOf().
+type RoleContextFunc func(ctx context.Context, sub, obj, act string) bool
+
+// Global handler function to auth token from http header, it will be
+// ignored when GTokenStore set to auth token locally.
 var GAuthHandlerFunc AuthHandlerFunc
 
 // Global handler function to verify role from http header
 var GRoleHandlerFunc RoleHandlerFunc
 
+// Global handler function to auth token with request context, it preferred
+// than GAuthHandlerFunc when set, such as:
+//
+//	mvc.GAuthContextFunc = wrpc.Singleton().AuthHeaderToken
+var GAuthContextFunc AuthContextFunc
+
+// Global handler function to verify role with request context, it preferred
+// than GRoleHandlerFunc when set, such as:
+//
+//	mvc.GRoleContextFunc = wrpc.Singleton().AuthHeaderRole
+var GRoleContextFunc RoleContextFunc
+
 // Get authoration and token from http header, than verify it and return account secures.
 //	@Return 401, 403, 405, 426 codes returned on error.
 func (c *WAuthController) AuthRequestHeader(hidelog ...bool) string {
-	uuid, _ := c.innerAuthHeader(len(hidelog) > 0 && hidelog[1])
+	uuid, _ := c.innerAuthHeader(len(hidelog) > 0 && hidelog[0])
 	return uuid
 }
 
@@ -212,50 +234,34 @@ func (c *WAuthController) DoAfterAuthUnmarshalXml(ps any, nextFunc3 NextFunc3, f
 
 // ------------------------------------------------------
 
-// Get authoration and token from http header, than verify it and return account secures.
+// Get auth headers by matched auth scheme, than verify it and return account secures.
 //	@return 401: Unsupport auth header (not found 'WENGOLD-V1.1'), or auth token failed.
 //	@return 403: Denied permission of user access the rest4 API.
 //	@return 405: Backend server not set AuthHanderFunc or RoleHandlerFunc.
 //	@return 426: Auth header must upgrade to 'WENGOLD-V1.1', deprecated 'WENGOLD'.
 func (c *WAuthController) innerAuthHeader(hidelog bool) (string, string) {
-	if GAuthHandlerFunc == nil || GRoleHandlerFunc == nil {
+	if GRoleHandlerFunc == nil && GRoleContextFunc == nil {
 		c.E405Disabled("Controller not set global handlers!")
 		return "", ""
 	}
 
-	// check authoration secure key
-	authoration := strings.ToUpper(c.Ctx.Request.Header.Get("Authoration"))
-	if authoration != "WENGOLD-V1.1" {
-		if strings.HasPrefix(authoration, "WENGOLD") {
-			c.E426UpgradeRequired("Upgrade required to WENGOLD-V1.1, not " + authoration)
-			return "", ""
-		}
-
-		c.E401Unauthed("Unsupport authoration: " + authoration)
+	// verify request headers by auth schemes
+	claims, state, err := c.authBySchemes()
+	if err != nil {
+		c.ErrorState(state, "Unauthed header, "+err.Error())
 		return "", ""
 	}
 
-	// get token from header and verify it and user role
-	if token := c.Ctx.Request.Header.Get("Token"); token != "" {
-		if uuid, pwd := GAuthHandlerFunc(token); uuid == "" {
-			c.E401Unauthed("Unauthed header token!")
-			return "", ""
-		} else {
-			if !GRoleHandlerFunc(uuid, c.Ctx.Input.URL(), c.Ctx.Request.Method) {
-				c.E403Denind("Role permission denied for " + uuid)
-				return "", ""
-			}
-
-			if !hidelog {
-				logger.D("Authenticated account:", uuid)
-			}
-			return uuid, pwd
-		}
+	uuid := claims.Uuid
+	if !c.verifyRole(uuid, c.Ctx.Input.URL(), c.Ctx.Request.Method) {
+		c.E403Denind("Role permission denied for " + uuid)
+		return "", ""
 	}
 
-	// token is empty or invalid, response unauthed
-	c.E401Unauthed("Unauthed header token!")
-	return "", ""
+	if !hidelog {
+		c.Logger().D("Authenticated account:", uuid, "by", claims.Scheme)
+	}
+	return uuid, claims.Pwd
 }
 
 // doAfterValidatedInner do bussiness action after success unmarshal params or
@@ -268,11 +274,9 @@ func (c *WAuthController) doAfterValidatedInner(datatype string,
 	}
 
 	// execute business function after unmarshal and validated
-	if status, resp := nextFunc2(uuid); resp != nil {
-		c.responCheckState(datatype, protect, hidelog, status, resp)
-	} else {
-		c.responCheckState(datatype, protect, hidelog, status)
-	}
+	c.responNext(outputType(datatype), protect, hidelog, func() (int, any) {
+		return nextFunc2(uuid)
+	})
 }
 
 // doAfterValidatedInner3 do bussiness action after success unmarshal params or
@@ -285,9 +289,16 @@ func (c *WAuthController) doAfterValidatedInner3(datatype string,
 	}
 
 	// execute business function after unmarshal and validated
-	if status, resp := nextFunc3(uuid, pwd); resp != nil {
-		c.responCheckState(datatype, protect, hidelog, status, resp)
-	} else {
-		c.responCheckState(datatype, protect, hidelog, status)
+	c.responNext(outputType(datatype), protect, hidelog, func() (int, any) {
+		return nextFunc3(uuid, pwd)
+	})
+}
+
+// authToken auth token by GAuthContextFunc with request context, or by
+// GAuthHandlerFunc when not set.
+func authToken(ctx context.Context, token string) (string, string) {
+	if GAuthContextFunc != nil {
+		return GAuthContextFunc(ctx, token)
 	}
+	return GAuthHandlerFunc(token)
 }
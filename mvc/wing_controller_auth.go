@@ -0,0 +1,287 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/astaxie/beego/context"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/secure"
+)
+
+// AuthClaims the authenticated account claims decoded by auth scheme.
+type AuthClaims struct {
+	Scheme  string   // auth scheme name which authed the request
+	Uuid    string   // account uuid, it must not empty when authed
+	Pwd     string   // optional account password
+	Role    string   // optional account role
+	Expires int64    // optional token expiration unix time in seconds
+	Extras  []string // optional extra datas decoded from token claims
+}
+
+// AuthScheme the authentication scheme of WAuthController to auth request headers.
+type AuthScheme interface {
+	// Name return the scheme name.
+	Name() string
+
+	// Match check whether the request carry the auth headers of scheme.
+	Match(ctx *context.Context) bool
+
+	// Auth verify the request and return claims, or return error state and error.
+	Auth(ctx *context.Context) (*AuthClaims, int, error)
+}
+
+// Auth scheme names of built-in schemes.
+const (
+	SchemeLegacy = "WENGOLD" // legacy scheme with Authoration and Token headers
+	SchemeBearer = "Bearer"  // standard Authorization Bearer JWT scheme
+	SchemeAPIKey = "APIKey"  // api key scheme with X-API-Key header
+)
+
+// Claims value names of BearerScheme layout.
+const (
+	ClaimPwd  = "pwd"  // account password
+	ClaimRole = "role" // account role
+)
+
+// The context data key of authed claims.
+const ctxClaimsKey = "wing-auth-claims"
+
+// legacyScheme the legacy auth scheme, it verify Token header by GTokenStore,
+// GAuthContextFunc or GAuthHandlerFunc when Authoration header is WENGOLD-V1.1.
+type legacyScheme struct{}
+
+// BearerScheme the standard 'Authorization: Bearer <jwt>' auth scheme, it verify
+// the jwt token locally by secure.ParseJwtClaims(), and decode the keyword of
+// claims by secure.DecClaims(), the first value is account uuid, and the others
+// are extra datas which named by Layout in order, such as:
+//
+//	// the keyword encoded by secure.EncClaims(uuid, pwd, role)
+//	bearer := &mvc.BearerScheme{Salt: jwtsalt, Layout: []string{mvc.ClaimPwd, mvc.ClaimRole}}
+type BearerScheme struct {
+	Salt   string   // jwt token signing salt
+	Layout []string // names of claims values after uuid, see ClaimPwd, ClaimRole
+}
+
+// APIKeyScheme the api key auth scheme for third-party integrators.
+type APIKeyScheme struct {
+	Header string // api key header name, default 'X-API-Key'
+
+	// Lookup return the claims of api key, or nil when invalid.
+	Lookup func(apikey string) *AuthClaims
+}
+
+var (
+	// LegacyScheme the default auth scheme of WAuthController.
+	LegacyScheme AuthScheme = &legacyScheme{}
+
+	// GAuthSchemes the global auth schemes of all WAuthControllers,
+	// the controllers can override it by RegisterAuthSchemes().
+	GAuthSchemes = []AuthScheme{LegacyScheme}
+
+	// ctlSchemes the auth schemes mapped by controller name.
+	ctlSchemes sync.Map
+)
+
+// RegisterAuthSchemes enable auth schemes of given controller, the schemes will
+// match request headers in order, and the first matched scheme used to auth.
+//
+// `USAGE` :
+//
+//	// enable legacy and bearer schemes for AccController
+//	bearer := &mvc.BearerScheme{Salt: jwtsalt}
+//	mvc.RegisterAuthSchemes("AccController", mvc.LegacyScheme, bearer)
+//
+//	// get claims in controller method
+//	c.DoAfterValidated(ps, func(uuid string) (int, any) {
+//		claims := c.Claims()
+//		...
+//	})
+func RegisterAuthSchemes(controller string, schemes ...AuthScheme) {
+	ctlSchemes.Store(controller, schemes)
+}
+
+// Claims return the authed claims of current request, or nil when unauthed.
+func (c *WAuthController) Claims() *AuthClaims {
+	if claims, ok := c.Ctx.Input.GetData(ctxClaimsKey).(*AuthClaims); ok {
+		return claims
+	}
+	return nil
+}
+
+// ----------------
+
+// authSchemes return the auth schemes of current controller.
+func (c *WAuthController) authSchemes() []AuthScheme {
+	ctl, _ := c.GetControllerAndAction()
+	if schemes, ok := ctlSchemes.Load(ctl); ok {
+		return schemes.([]AuthScheme)
+	}
+	return GAuthSchemes
+}
+
+// authBySchemes auth request by the first matched scheme, it use the first
+// scheme to auth and response its errors when none scheme matched.
+func (c *WAuthController) authBySchemes() (*AuthClaims, int, error) {
+	schemes := c.authSchemes()
+	if len(schemes) == 0 {
+		return nil, invar.E405FuncDisabled, invar.ErrInvalidConfigs
+	}
+
+	scheme := schemes[0]
+	for _, s := range schemes {
+		if s.Match(c.Ctx) {
+			scheme = s
+			break
+		}
+	}
+
+	claims, state, err := scheme.Auth(c.Ctx)
+	if err != nil {
+		return nil, state, err
+	} else if claims == nil || claims.Uuid == "" {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidToken
+	}
+
+	// copy claims for it maybe shared by scheme lookup
+	authed := *claims
+	authed.Scheme = scheme.Name()
+	c.Ctx.Input.SetData(ctxClaimsKey, &authed)
+	setAccessUuid(c.Ctx, authed.Uuid)
+	return &authed, invar.StatusOK, nil
+}
+
+// verifyRole verify role access permission by GRoleContextFunc with request
+// context, or by GRoleHandlerFunc when not set.
+func (c *WAuthController) verifyRole(sub, obj, act string) bool {
+	if GRoleContextFunc != nil {
+		return GRoleContextFunc(c.Ctx.Request.Context(), sub, obj, act)
+	}
+	return GRoleHandlerFunc(sub, obj, act)
+}
+
+// Name return legacy scheme name.
+func (s *legacyScheme) Name() string { return SchemeLegacy }
+
+// Match check Authoration header exist.
+func (s *legacyScheme) Match(ctx *context.Context) bool {
+	return ctx.Input.Header("Authoration") != ""
+}
+
+// Auth verify Token header when Authoration header is WENGOLD-V1.1.
+func (s *legacyScheme) Auth(ctx *context.Context) (*AuthClaims, int, error) {
+	if GAuthHandlerFunc == nil && GAuthContextFunc == nil && GTokenStore == nil {
+		return nil, invar.E405FuncDisabled, invar.ErrInvalidConfigs
+	}
+
+	// check authoration secure key
+	authoration := strings.ToUpper(ctx.Input.Header("Authoration"))
+	if authoration != "WENGOLD-V1.1" {
+		if strings.HasPrefix(authoration, "WENGOLD") {
+			return nil, invar.E426UpgradeRequired, invar.ErrInvalidClient
+		}
+		return nil, invar.E401Unauthorized, invar.ErrInvalidClient
+	}
+
+	// get token from header and verify it
+	if token := ctx.Input.Header("Token"); token != "" {
+		if GTokenStore != nil {
+			if ts, err := GTokenStore.Verify(token); err == nil {
+				return &AuthClaims{Uuid: ts.Uuid, Role: ts.Role}, invar.StatusOK, nil
+			}
+		} else if uuid, pwd := authToken(ctx.Request.Context(), token); uuid != "" {
+			return &AuthClaims{Uuid: uuid, Pwd: pwd}, invar.StatusOK, nil
+		}
+	}
+	return nil, invar.E401Unauthorized, invar.ErrInvalidToken
+}
+
+// Name return bearer scheme name.
+func (s *BearerScheme) Name() string { return SchemeBearer }
+
+// Match check Authorization header start with 'Bearer '.
+func (s *BearerScheme) Match(ctx *context.Context) bool {
+	_, ok := s.token(ctx)
+	return ok
+}
+
+// Auth verify bearer jwt token and decode claims.
+func (s *BearerScheme) Auth(ctx *context.Context) (*AuthClaims, int, error) {
+	token, ok := s.token(ctx)
+	if !ok || token == "" || s.Salt == "" {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidToken
+	}
+
+	jc, err := secure.ParseJwtClaims(token, s.Salt)
+	if err != nil {
+		return nil, invar.E401Unauthorized, err
+	}
+
+	sets, err := secure.DecClaims(jc.Keyword)
+	if err != nil || len(sets) == 0 {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidToken
+	}
+
+	claims := &AuthClaims{Uuid: sets[0], Expires: jc.ExpiresAt, Extras: sets[1:]}
+	for i, name := range s.Layout {
+		if i >= len(claims.Extras) {
+			break
+		}
+
+		switch name {
+		case ClaimPwd:
+			claims.Pwd = claims.Extras[i]
+		case ClaimRole:
+			claims.Role = claims.Extras[i]
+		}
+	}
+	return claims, invar.StatusOK, nil
+}
+
+// token return the bearer token from Authorization header.
+func (s *BearerScheme) token(ctx *context.Context) (string, bool) {
+	auth := ctx.Input.Header("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:]), true
+	}
+	return "", false
+}
+
+// Name return api key scheme name.
+func (s *APIKeyScheme) Name() string { return SchemeAPIKey }
+
+// Match check api key header exist.
+func (s *APIKeyScheme) Match(ctx *context.Context) bool {
+	return ctx.Input.Header(s.header()) != ""
+}
+
+// Auth lookup the claims of api key.
+func (s *APIKeyScheme) Auth(ctx *context.Context) (*AuthClaims, int, error) {
+	apikey := ctx.Input.Header(s.header())
+	if apikey == "" || s.Lookup == nil {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidToken
+	}
+
+	if claims := s.Lookup(apikey); claims != nil {
+		return claims, invar.StatusOK, nil
+	}
+	return nil, invar.E401Unauthorized, invar.ErrInvalidToken
+}
+
+// header return the api key header name.
+func (s *APIKeyScheme) header() string {
+	if s.Header != "" {
+		return s.Header
+	}
+	return "X-API-Key"
+}
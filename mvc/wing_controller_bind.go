@@ -0,0 +1,297 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+)
+
+// Input params sources of binding, the response data type is json.
+const (
+	bindQuery = "query" // bind params from url query and router path
+	bindForm  = "form"  // bind params from url query, post form and router path
+)
+
+// ParamGetter return the router path param value by key like ':id'.
+type ParamGetter func(key string) string
+
+// DoAfterValidatedQuery do bussiness action after success bind and validate
+// the url query and router path params, the struct fields bind by 'query'
+// tag, or 'form', 'json' tags if not set.
+//
+// ---
+//
+//	type ListParams struct {
+//		ID    int64    `query:"id"    validate:"gt=0"` // bind router '/list/:id'
+//		Page  int      `query:"page"  validate:"gte=0"`
+//		Tags  []string `query:"tags"`                  // bind '?tags=a&tags=b'
+//		IDs   []int64  `query:"ids,csv"`               // bind '?ids=1,2,3'
+//		Alive bool     `query:"alive"`
+//	}
+//
+//	//	@router /list/:id [get]
+//	func (c *AccController) List() {
+//		ps := &types.ListParams{}
+//		c.DoAfterValidatedQuery(ps, func() (int, any) {
+//			return http.StatusOK, service.List(ps)
+//		})
+//	}
+//
+//	@Return 400, 404 codes returned on error.
+func (c *WingController) DoAfterValidatedQuery(ps any, nextFunc NextFunc, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	c.doAfterValidatedInner(bindQuery, ps, nextFunc, true, protect, hidelog)
+}
+
+// DoAfterValidatedForm do bussiness action after success bind and validate the
+// url query, post form and router path params, the struct fields bind by 'form'
+// tag, or 'query', 'json' tags if not set.
+//
+//	@Return 400, 404 codes returned on error.
+func (c *WingController) DoAfterValidatedForm(ps any, nextFunc NextFunc, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	c.doAfterValidatedInner(bindForm, ps, nextFunc, true, protect, hidelog)
+}
+
+// DoAfterValidatedQuery do bussiness action after success bind and validate the url query params.
+//
+//	@Return 400, 401, 403, 404, 405, 426 codes returned on error.
+func (c *WAuthController) DoAfterValidatedQuery(ps any, nextFunc2 NextFunc2, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	if uuid, _ := c.innerAuthHeader(hidelog); uuid != "" {
+		c.doAfterValidatedInner(bindQuery, ps, nextFunc2, uuid, true, protect, hidelog)
+	}
+}
+
+// DoAfterValidatedForm do bussiness action after success bind and validate the post form params.
+//
+//	@Return 400, 401, 403, 404, 405, 426 codes returned on error.
+func (c *WAuthController) DoAfterValidatedForm(ps any, nextFunc2 NextFunc2, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	if uuid, _ := c.innerAuthHeader(hidelog); uuid != "" {
+		c.doAfterValidatedInner(bindForm, ps, nextFunc2, uuid, true, protect, hidelog)
+	}
+}
+
+// DoAfterAuthValidatedQuery do bussiness action after success bind and validate the url query params.
+//
+//	@Return 400, 401, 403, 404, 405, 426 codes returned on error.
+func (c *WAuthController) DoAfterAuthValidatedQuery(ps any, nextFunc3 NextFunc3, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	if uuid, pwd := c.innerAuthHeader(hidelog); uuid != "" {
+		c.doAfterValidatedInner3(bindQuery, ps, nextFunc3, uuid, pwd, true, protect, hidelog)
+	}
+}
+
+// DoAfterAuthValidatedForm do bussiness action after success bind and validate the post form params.
+//
+//	@Return 400, 401, 403, 404, 405, 426 codes returned on error.
+func (c *WAuthController) DoAfterAuthValidatedForm(ps any, nextFunc3 NextFunc3, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	if uuid, pwd := c.innerAuthHeader(hidelog); uuid != "" {
+		c.doAfterValidatedInner3(bindForm, ps, nextFunc3, uuid, pwd, true, protect, hidelog)
+	}
+}
+
+// ----------------
+
+// bindParams bind url query, post form and router path params into struct.
+func (c *WingController) bindParams(source string, ps any) error {
+	values, r := url.Values{}, c.Ctx.Request
+	if source == bindForm {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			if err := r.ParseMultipartForm(beego.BConfig.MaxMemory); err != nil {
+				return err
+			}
+		} else if err := r.ParseForm(); err != nil {
+			return err
+		}
+		values = r.Form
+	} else if r.URL != nil {
+		values = r.URL.Query()
+	}
+	return BindValues(ps, values, source, c.Ctx.Input.Param)
+}
+
+// outputType return the response data type of given input params type,
+// it always response json for query and form params.
+func outputType(datatype string) string {
+	switch datatype {
+	case bindQuery, bindForm:
+		return "json"
+	}
+	return datatype
+}
+
+// BindValues bind url values and router path params into the struct pointer,
+// the fields bind by given tag name, or 'form', 'query', 'json' tags if not set,
+// and the router path param used when value not found in url values.
+//
+// It support string, bool, int, uint, float kinds, and slices or pointers of them,
+// the slice bind repeated values as 'k=a&k=b', or split comma separated value
+// as 'k=a,b' when the tag has 'csv' option.
+func BindValues(ps any, values url.Values, tag string, param ParamGetter) error {
+	rv := reflect.ValueOf(ps)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("Bind target must be struct pointer")
+	}
+	return bindStruct(rv.Elem(), values, tag, param)
+}
+
+// bindStruct bind values into struct fields, and embedded structs.
+func bindStruct(rv reflect.Value, values url.Values, tag string, param ParamGetter) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field, fv := rt.Field(i), rv.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := bindStruct(fv, values, tag, param); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, csv := bindFieldName(field, tag)
+		if name == "" {
+			continue
+		}
+
+		strs, ok := values[name]
+		if !ok && param != nil {
+			if pv := param(":" + name); pv != "" {
+				strs, ok = []string{pv}, true
+			}
+		}
+
+		if ok && len(strs) > 0 {
+			if err := setFieldValues(fv, strs, csv); err != nil {
+				return errors.New("Invalid field " + name + ", " + err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// bindFieldName return the field bind name by tags, or empty to skip, and
+// whether the tag has 'csv' option to split comma separated value.
+func bindFieldName(field reflect.StructField, tag string) (string, bool) {
+	for _, key := range []string{tag, bindForm, bindQuery, "json"} {
+		if value, ok := field.Tag.Lookup(key); ok {
+			opts := strings.Split(value, ",")
+			if name := opts[0]; name == "-" {
+				return "", false
+			} else if name != "" {
+				for _, opt := range opts[1:] {
+					if opt == "csv" {
+						return name, true
+					}
+				}
+				return name, false
+			}
+		}
+	}
+	return field.Name, false
+}
+
+// setFieldValues set string values into field with type conversion, the
+// single value split by comma for slice field when csv is true.
+func setFieldValues(fv reflect.Value, strs []string, csv bool) error {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		pv := reflect.New(fv.Type().Elem())
+		if err := setFieldValues(pv.Elem(), strs, csv); err != nil {
+			return err
+		}
+		fv.Set(pv)
+		return nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 { // []byte
+			fv.SetBytes([]byte(strs[0]))
+			return nil
+		}
+
+		// split 'k=a,b' format only when opt in by 'csv' option
+		if csv && len(strs) == 1 {
+			strs = strings.Split(strs[0], ",")
+			for i := range strs {
+				strs[i] = strings.TrimSpace(strs[i])
+			}
+		}
+
+		sv := reflect.MakeSlice(fv.Type(), len(strs), len(strs))
+		for i, str := range strs {
+			if err := setFieldValue(sv.Index(i), str); err != nil {
+				return err
+			}
+		}
+		fv.Set(sv)
+		return nil
+	}
+	return setFieldValue(fv, strs[0])
+}
+
+// setFieldValue set string value into basic kind field.
+func setFieldValue(fv reflect.Value, str string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(str)
+	case reflect.Bool:
+		if str == "" {
+			return nil
+		}
+		v, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if str == "" {
+			return nil
+		}
+		v, err := strconv.ParseInt(str, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if str == "" {
+			return nil
+		}
+		v, err := strconv.ParseUint(str, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		if str == "" {
+			return nil
+		}
+		v, err := strconv.ParseFloat(str, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(v)
+	case reflect.Ptr, reflect.Slice:
+		return setFieldValues(fv, []string{str}, false)
+	default:
+		return errors.New("unsupported type " + fv.Type().String())
+	}
+	return nil
+}
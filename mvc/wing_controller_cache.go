@@ -0,0 +1,238 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachePolicy the http cache policy of routers, it only work for GET and HEAD
+// requests with 200 status responsed by ResponJSON, ResponXML, ResponYAML and
+// the other Respon* helpers except jsonp.
+type CachePolicy struct {
+	MaxAge  time.Duration // max-age of Cache-Control, 0 to revalidate every time
+	Private bool          // only allow client private cache, not proxies
+	NoStore bool          // disable cache totally
+	ETag    bool          // compute strong ETag from serialized response body
+}
+
+// cacheVersion the caller-supplied version of response data.
+type cacheVersion struct {
+	etag     string
+	modified time.Time
+}
+
+// routePolicy the cache policy of router pattern.
+type routePolicy struct {
+	pattern string
+	policy  *CachePolicy
+}
+
+// The context data keys of cache policy and version.
+const (
+	ctxCachePolicyKey  = "wing-cache-policy"
+	ctxCacheVersionKey = "wing-cache-version"
+)
+
+var (
+	cacheLock     sync.RWMutex
+	cachePolicies []*routePolicy
+)
+
+// RegisterCachePolicy register cache policy of routers, the pattern support
+// wildcards as MatchRoute(), and the first registered matched policy used.
+//
+// ---
+//
+//	// revalidate profile by etag every time
+//	mvc.RegisterCachePolicy("/v3/acc/profile", &mvc.CachePolicy{ETag: true, Private: true})
+//
+//	// cache catalogs 10 minutes
+//	mvc.RegisterCachePolicy("/v3/mall/catalog/**", &mvc.CachePolicy{ETag: true, MaxAge: 10 * time.Minute})
+func RegisterCachePolicy(pattern string, policy *CachePolicy) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	cachePolicies = append(cachePolicies, &routePolicy{pattern: pattern, policy: policy})
+}
+
+// SetCachePolicy set cache policy of current request, it override the registered policy.
+func (c *WingController) SetCachePolicy(policy *CachePolicy) {
+	c.Ctx.Input.SetData(ctxCachePolicyKey, policy)
+}
+
+// SetCacheVersion set the version and optional last modified time of response
+// data, it use the version as ETag instead of compute from response body, and
+// response 304 without query datas when matched.
+//
+// ---
+//
+//	if c.SetCacheVersion(strconv.FormatInt(profile.Version, 10), profile.Updated) {
+//		return // not modified, 304 responsed
+//	}
+//	c.ResponJSON(http.StatusOK, service.GetProfile(uuid))
+func (c *WingController) SetCacheVersion(version string, modified ...time.Time) bool {
+	cv := &cacheVersion{etag: quoteETag(version)}
+	if len(modified) > 0 {
+		cv.modified = modified[0]
+	}
+	c.Ctx.Input.SetData(ctxCacheVersionKey, cv)
+
+	if c.isCacheable() && c.notModified(cv.etag, cv.modified) {
+		c.setCacheHeaders(c.cachePolicy(), cv.etag, cv.modified)
+		c.responNotModified()
+		return true
+	}
+	return false
+}
+
+// ----------------
+
+// serveCached serve response body with cache headers, or 304 when client cache
+// not modified, it return false when current request not handled.
+func (c *WingController) serveCached(datatype string) bool {
+	if !c.isCacheable() || datatype == "jsonp" {
+		return false
+	}
+
+	policy := c.cachePolicy()
+	cv, _ := c.Ctx.Input.GetData(ctxCacheVersionKey).(*cacheVersion)
+	if policy == nil && cv == nil {
+		return false
+	} else if policy != nil && policy.NoStore {
+		c.setCacheHeaders(policy, "", time.Time{})
+		return false
+	}
+
+	// use caller-supplied version as etag
+	if cv != nil {
+		c.setCacheHeaders(policy, cv.etag, cv.modified)
+		if c.notModified(cv.etag, cv.modified) {
+			c.responNotModified()
+			return true
+		}
+		return false
+	} else if !policy.ETag {
+		c.setCacheHeaders(policy, "", time.Time{})
+		return false
+	}
+
+	// compute strong etag from serialized body
+	content, ctype, err := marshalBody(datatype, c.Data[datatype])
+	if err != nil {
+		return false
+	}
+
+	sum := sha256.Sum256(content)
+	etag := quoteETag(hex.EncodeToString(sum[:16]))
+	c.setCacheHeaders(policy, etag, time.Time{})
+	if c.notModified(etag, time.Time{}) {
+		c.responNotModified()
+		return true
+	}
+
+	c.writeBody(content, ctype)
+	return true
+}
+
+// isCacheable check current request method whether cacheable.
+func (c *WingController) isCacheable() bool {
+	method := c.Ctx.Request.Method
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// cachePolicy return the cache policy of current request.
+func (c *WingController) cachePolicy() *CachePolicy {
+	if policy, ok := c.Ctx.Input.GetData(ctxCachePolicyKey).(*CachePolicy); ok {
+		return policy
+	}
+
+	cacheLock.RLock()
+	defer cacheLock.RUnlock()
+	url := c.Ctx.Input.URL()
+	for _, rp := range cachePolicies {
+		if MatchRoute(rp.pattern, url) {
+			return rp.policy
+		}
+	}
+	return nil
+}
+
+// setCacheHeaders set Cache-Control, ETag and Last-Modified headers.
+func (c *WingController) setCacheHeaders(policy *CachePolicy, etag string, modified time.Time) {
+	out := c.Ctx.Output
+	if policy != nil {
+		switch {
+		case policy.NoStore:
+			out.Header("Cache-Control", "no-store")
+		case policy.MaxAge <= 0:
+			out.Header("Cache-Control", "no-cache")
+		default:
+			scope := "public"
+			if policy.Private {
+				scope = "private"
+			}
+			maxage := strconv.FormatInt(int64(policy.MaxAge/time.Second), 10)
+			out.Header("Cache-Control", scope+", max-age="+maxage)
+		}
+	}
+
+	if etag != "" {
+		out.Header("ETag", etag)
+	}
+	if !modified.IsZero() {
+		out.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified check If-None-Match and If-Modified-Since headers, the
+// If-Modified-Since will be ignored when If-None-Match exist.
+func (c *WingController) notModified(etag string, modified time.Time) bool {
+	if inm := c.Ctx.Input.Header("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.Ctx.Input.Header("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !modified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// responNotModified response 304 status without body.
+func (c *WingController) responNotModified() {
+	c.Ctx.Output.Status = http.StatusNotModified
+	c.Ctx.ResponseWriter.WriteHeader(http.StatusNotModified)
+}
+
+// quoteETag quote the etag value if not quoted.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, "\"") || strings.HasPrefix(etag, "W/\"") {
+		return etag
+	}
+	return "\"" + etag + "\""
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/wengoldx/wcore/invar"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v2"
+)
+
+// Content types of binary codecs for machine clients.
+const (
+	MIMEProtobuf = "application/x-protobuf"
+	MIMEMsgpack  = "application/msgpack"
+)
+
+// Data types of binary codecs, the msgpack codec encode struct fields by
+// 'json' tags, so the same params struct can serve json and msgpack.
+const (
+	dataProtobuf = "protobuf"
+	dataMsgpack  = "msgpack"
+)
+
+// acceptRange the media range and quality of Accept header.
+type acceptRange struct {
+	mtype   string  // main type, such as 'application', or '*'
+	subtype string  // sub type, such as 'json', or '*'
+	quality float64 // quality value in 0 ~ 1
+}
+
+// The content types of negotiable response data types.
+var dataMimes = map[string][]string{
+	"json":       {"application/json"},
+	"xml":        {"application/xml", "text/xml"},
+	"yaml":       {"application/x-yaml", "application/yaml", "text/yaml"},
+	dataProtobuf: {MIMEProtobuf},
+	dataMsgpack:  {MIMEMsgpack, "application/x-msgpack"},
+}
+
+// ----------------
+
+// requestType return the real decode type of request body, it decode protobuf
+// or msgpack body when Content-Type header indicated, otherwise use datatype.
+func (c *WingController) requestType(datatype string) string {
+	if datatype == "json" || datatype == "xml" {
+		if dt := mimeDataType(c.Ctx.Input.Header("Content-Type")); dt != "" {
+			return dt
+		}
+	}
+	return datatype
+}
+
+// responseType return the real encode type of response data, it encode protobuf
+// or msgpack data when Accept header prefer them by quality values, otherwise use
+// datatype. The protobuf only work for proto.Message data, and the Vary header
+// will set for the response format negotiated by Accept header.
+func (c *WingController) responseType(datatype string, data ...any) string {
+	if datatype != "json" && datatype != "xml" && datatype != "yaml" {
+		return datatype
+	}
+
+	c.Ctx.ResponseWriter.Header().Add("Vary", "Accept")
+	ranges := parseAccept(c.Ctx.Input.Header("Accept"))
+	if len(ranges) == 0 {
+		return datatype
+	}
+
+	candidates := []string{dataMsgpack}
+	if len(data) > 0 {
+		if _, ok := data[0].(proto.Message); ok {
+			candidates = append(candidates, dataProtobuf)
+		}
+	}
+
+	// keep datatype when same quality
+	best, quality := datatype, acceptQuality(ranges, datatype)
+	for _, candidate := range candidates {
+		if q := acceptQuality(ranges, candidate); q > quality {
+			best, quality = candidate, q
+		}
+	}
+	return best
+}
+
+// serveBody serve json, xml, yaml, protobuf or msgpack response data, and
+// compress it when client accepted.
+func (c *WingController) serveBody(datatype string) {
+	content, ctype, err := marshalBody(datatype, c.Data[datatype])
+	if err != nil {
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.writeBody(content, ctype)
+}
+
+// unmarshalBinary unmarshal protobuf or msgpack request body.
+func unmarshalBinary(datatype string, body []byte, ps any) error {
+	switch datatype {
+	case dataProtobuf:
+		msg, ok := ps.(proto.Message)
+		if !ok {
+			return invar.ErrUnsupportFormat
+		}
+		return proto.Unmarshal(body, msg)
+	case dataMsgpack:
+		dec := msgpack.NewDecoder(bytes.NewReader(body))
+		dec.SetCustomStructTag("json")
+		return dec.Decode(ps)
+	}
+	return invar.ErrUnsupportFormat
+}
+
+// mimeDataType return binary data type of the given Content-Type header, it
+// return empty string when not binary content types.
+func mimeDataType(header string) string {
+	header = strings.ToLower(header)
+	switch {
+	case strings.Contains(header, MIMEProtobuf):
+		return dataProtobuf
+	case strings.Contains(header, MIMEMsgpack), strings.Contains(header, "application/x-msgpack"):
+		return dataMsgpack
+	}
+	return ""
+}
+
+// parseAccept parse media ranges and quality values of Accept header.
+func parseAccept(header string) []*acceptRange {
+	ranges := []*acceptRange{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mtype, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || mtype == "" || subtype == "" {
+			continue
+		}
+
+		ar := &acceptRange{mtype: mtype, subtype: subtype, quality: 1}
+		for _, param := range params[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value >= 0 && value <= 1 {
+					ar.quality = value
+				}
+			}
+		}
+		ranges = append(ranges, ar)
+	}
+	return ranges
+}
+
+// acceptQuality return the quality of data type by the most specific matched
+// media range, or 0 when not acceptable.
+func acceptQuality(ranges []*acceptRange, datatype string) float64 {
+	quality, specific := 0.0, -1
+	for _, mime := range dataMimes[datatype] {
+		mtype, subtype, _ := strings.Cut(mime, "/")
+		for _, ar := range ranges {
+			level := -1
+			switch {
+			case ar.mtype == mtype && ar.subtype == subtype:
+				level = 2
+			case ar.mtype == mtype && ar.subtype == "*":
+				level = 1
+			case ar.mtype == "*" && ar.subtype == "*":
+				level = 0
+			}
+
+			if level > specific || (level == specific && level >= 0 && ar.quality > quality) {
+				quality, specific = ar.quality, level
+			}
+		}
+	}
+	return quality
+}
+
+// marshalBody serialize response data same as beego output, and return
+// the content type of datatype.
+func marshalBody(datatype string, data any) ([]byte, string, error) {
+	hasIndent := beego.BConfig.RunMode != beego.PROD
+	switch datatype {
+	case "json":
+		if hasIndent {
+			content, err := json.MarshalIndent(data, "", "  ")
+			return content, "application/json; charset=utf-8", err
+		}
+		content, err := json.Marshal(data)
+		return content, "application/json; charset=utf-8", err
+	case "xml":
+		if hasIndent {
+			content, err := xml.MarshalIndent(data, "", "  ")
+			return content, "application/xml; charset=utf-8", err
+		}
+		content, err := xml.Marshal(data)
+		return content, "application/xml; charset=utf-8", err
+	case "yaml":
+		content, err := yaml.Marshal(data)
+		return content, "application/x-yaml; charset=utf-8", err
+	case dataProtobuf:
+		msg, ok := data.(proto.Message)
+		if !ok {
+			return nil, "", invar.ErrUnsupportFormat
+		}
+		content, err := proto.Marshal(msg)
+		return content, MIMEProtobuf, err
+	case dataMsgpack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		err := enc.Encode(data)
+		return buf.Bytes(), MIMEMsgpack, err
+	}
+	return nil, "", invar.ErrUnsupportFormat
+}
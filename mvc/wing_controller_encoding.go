@@ -0,0 +1,330 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// bodyLimit the max request body size of router pattern.
+type bodyLimit struct {
+	pattern string
+	limit   int64
+}
+
+// Content encodings of request and response bodies.
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+	encodingBr      = "br"
+)
+
+var (
+	// DefaultBodyLimit the max request body size of routers not registered by
+	// RegisterBodyLimit(), it use beego MaxMemory config when not set.
+	DefaultBodyLimit int64 = 0
+
+	// CompressMinSize the min response body size to compress, the small body
+	// output directly for compress them not save bandwidth.
+	CompressMinSize = 1024
+
+	// compressEnabled whether compress response body by Accept-Encoding header.
+	compressEnabled = false
+
+	bodyLock   sync.RWMutex
+	bodyLimits []*bodyLimit
+
+	errBodyTooLarge = errors.New("request body too large")
+)
+
+// RegisterBodyLimit register the max request body size of router pattern, the
+// first registered pattern matched by MatchRoute() used. Notice that beego
+// reject the body bigger than MaxMemory config after decoded, so set the
+// 'maxmemory' config of app.conf file when limit bigger than it.
+//
+// ---
+//
+//	mvc.RegisterBodyLimit("/v3/device/samples", 32<<20) // 32MB
+//	mvc.RegisterBodyLimit("/v3/acc/**", 64<<10)         // 64KB
+func RegisterBodyLimit(pattern string, limit int64) {
+	bodyLock.Lock()
+	defer bodyLock.Unlock()
+	for _, bl := range bodyLimits {
+		if bl.pattern == pattern {
+			bl.limit = limit
+			return
+		}
+	}
+	bodyLimits = append(bodyLimits, &bodyLimit{pattern: pattern, limit: limit})
+}
+
+// UseBodyFilter limit request body sizes by routers and decode compressed
+// request body by Content-Encoding header, it response 413 when body size
+// over limit before or after decoded, 400 for invalid compressed data, and
+// 415 for unsupported encodings. The gzip and deflate encodings supported.
+//
+// ---
+//
+//	// call it before utils.HttpServer()
+//	mvc.RegisterBodyLimit("/v3/device/samples", 32<<20)
+//	mvc.UseBodyFilter()
+//	utils.HttpServer()
+func UseBodyFilter(limit ...int64) {
+	if len(limit) > 0 && limit[0] > 0 {
+		DefaultBodyLimit = limit[0]
+	}
+
+	// beego copy request body before router filters, so filter it before static
+	beego.InsertFilter("*", beego.BeforeStatic, bodyFilter)
+	logger.I("Using request body filter")
+}
+
+// UseCompression compress the response body of Respon* helpers by gzip or br
+// encoding negotiated by Accept-Encoding header, and the body smaller than
+// the given min size output directly.
+//
+// ---
+//
+//	// call it before utils.HttpServer()
+//	mvc.UseCompression(2048)
+//	utils.HttpServer()
+func UseCompression(minsize ...int) {
+	if len(minsize) > 0 && minsize[0] >= 0 {
+		CompressMinSize = minsize[0]
+	}
+	compressEnabled = true
+}
+
+// ----------------
+
+// bodyFilter limit request body size and decode compressed request body.
+func bodyFilter(ctx *context.Context) {
+	r := ctx.Request
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Body == nil {
+		return
+	}
+
+	if ctx.Input.IsUpload() {
+		return // multipart files limited by UploadMaxBody
+	}
+
+	limit := routeBodyLimit(ctx.Input.URL())
+	if r.ContentLength > limit {
+		bodyErrorState(ctx, invar.E413TooLarge, "body size over "+strconv.FormatInt(limit, 10))
+		return
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		if r.ContentLength >= 0 {
+			return // the Content-Length checked, only read chunked body
+		}
+	case encodingGzip, encodingDeflate:
+	default:
+		bodyErrorState(ctx, invar.E415UnsupportedMedia, "unsupported encoding "+encoding)
+		return
+	}
+
+	body, err := decodeBody(encoding, r.Body, limit)
+	r.Body.Close()
+	if err != nil {
+		if errors.Is(err, errBodyTooLarge) {
+			bodyErrorState(ctx, invar.E413TooLarge, "body size over "+strconv.FormatInt(limit, 10))
+		} else {
+			bodyErrorState(ctx, invar.E400ParseParams, "decode "+encoding+" body, err:"+err.Error())
+		}
+		return
+	}
+
+	// replace with decoded body, and avoid beego decode it again
+	r.Header.Del("Content-Encoding")
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
+
+// routeBodyLimit return the max request body size of url.
+func routeBodyLimit(url string) int64 {
+	bodyLock.RLock()
+	defer bodyLock.RUnlock()
+	for _, bl := range bodyLimits {
+		if MatchRoute(bl.pattern, url) {
+			return bl.limit
+		}
+	}
+
+	if DefaultBodyLimit > 0 {
+		return DefaultBodyLimit
+	}
+	return beego.BConfig.MaxMemory
+}
+
+// bodyErrorState response error state from body filter.
+func bodyErrorState(ctx *context.Context, state int, errmsg string) {
+	logger.Trace(GetRequestID(ctx)).E("Respone ERR:", state, ">", ctx.Input.URL(), errmsg)
+	body := &ErrorBody{Status: state, Code: errorCodes[state], Message: invar.StatusText(state)}
+	if ErrorBodyDetail {
+		body.Detail = errmsg
+	}
+	writeErrorBody(ctx, body)
+}
+
+// decodeBody read and decode compressed or plain body, it return errBodyTooLarge
+// when body size over limit before or after decoded, for defend compress bomb.
+func decodeBody(encoding string, body io.Reader, limit int64) ([]byte, error) {
+	raw, err := readLimited(body, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.ReadCloser
+	switch encoding {
+	case "", "identity":
+		return raw, nil
+	case encodingGzip:
+		if reader, err = gzip.NewReader(bytes.NewReader(raw)); err != nil {
+			return nil, err
+		}
+	case encodingDeflate:
+		// deflate should be zlib format, but some clients send raw deflate
+		if reader, err = zlib.NewReader(bytes.NewReader(raw)); err != nil {
+			reader = flate.NewReader(bytes.NewReader(raw))
+		}
+	default:
+		return nil, invar.ErrUnsupportFormat
+	}
+	defer reader.Close()
+	return readLimited(reader, limit)
+}
+
+// readLimited read all data, it return errBodyTooLarge when data over limit.
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(data)) > limit {
+		return nil, errBodyTooLarge
+	}
+	return data, nil
+}
+
+// acceptEncoding return the response encoding negotiated by Accept-Encoding
+// header, it return empty string when compression disabled or body too small.
+func (c *WingController) acceptEncoding(size int) string {
+	if !compressEnabled || size < CompressMinSize {
+		return ""
+	}
+	return negotiateEncoding(c.Ctx.Input.Header("Accept-Encoding"))
+}
+
+// writeBody write response body with content type, and compress it when
+// client accepted, the strong etag changed to weak for compressed body.
+func (c *WingController) writeBody(content []byte, ctype string) {
+	out := c.Ctx.Output
+	out.Header("Content-Type", ctype)
+
+	encoding := c.acceptEncoding(len(content))
+	if encoding == "" {
+		out.Body(content)
+		return
+	}
+
+	compressed, err := compressBody(encoding, content)
+	if err != nil {
+		logger.W("Compress", encoding, "body, err:", err)
+		out.Body(content)
+		return
+	}
+
+	// write directly, beego output may compress it again
+	w := c.Ctx.ResponseWriter
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Length", strconv.Itoa(len(compressed)))
+	if etag := w.Header().Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		w.Header().Set("ETag", "W/"+etag)
+	}
+	if out.Status != 0 {
+		w.WriteHeader(out.Status)
+		out.Status = 0
+	}
+	w.Write(compressed)
+}
+
+// negotiateEncoding return br or gzip encoding by Accept-Encoding header, the
+// br preferred when both accepted with same quality.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(coding))] = quality
+	}
+
+	encoding, best := "", 0.0
+	for _, coding := range []string{encodingBr, encodingGzip} {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > best {
+			encoding, best = coding, quality
+		}
+	}
+	return encoding
+}
+
+// compressBody compress content by gzip or br encoding.
+func compressBody(encoding string, content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case encodingGzip:
+		writer = gzip.NewWriter(&buf)
+	case encodingBr:
+		writer = brotli.NewWriter(&buf)
+	default:
+		return nil, invar.ErrUnsupportFormat
+	}
+
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return nil, err
+	} else if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
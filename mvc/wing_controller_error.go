@@ -0,0 +1,165 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/go-playground/validator/v10"
+	"github.com/wengoldx/wcore/invar"
+)
+
+// ErrorBody the structured error body response to client when ErrorBodyEnabled
+// is true or validate failed, the Detail only output when ErrorBodyDetail is
+// true, and the Fields messages are translated by request language and keyed by
+// field names, see UseJSONFieldNames(), the xml body output them as field list.
+//
+// ---
+//
+//	{
+//		"status": 400,
+//		"code": 4103,
+//		"message": "Parse Input Params Error",
+//		"detail": "Key: 'Accout.Acc' Error:Field validation for 'Acc' failed on the 'required' tag",
+//		"fields": {"acc": "acc为必填字段"}
+//	}
+type ErrorBody struct {
+	XMLName xml.Name          `json:"-" xml:"error" yaml:"-"`
+	Status  int               `json:"status"           xml:"status"           yaml:"status"`
+	Code    int               `json:"code,omitempty"    xml:"code,omitempty"    yaml:"code,omitempty"`
+	Message string            `json:"message"          xml:"message"          yaml:"message"`
+	Detail  string            `json:"detail,omitempty"  xml:"detail,omitempty"  yaml:"detail,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"  xml:"-"            yaml:"fields,omitempty"`
+	XFields []FieldError      `json:"-"                 xml:"fields>field" yaml:"-"`
+}
+
+// FieldError the validate error of one input params field in xml error body.
+type FieldError struct {
+	Field   string `json:"field"   xml:"name,attr"  yaml:"field"`
+	Tag     string `json:"tag"     xml:"tag,attr"   yaml:"tag"`
+	Message string `json:"message" xml:",chardata"  yaml:"message"`
+}
+
+var (
+	// ErrorBodyEnabled response structured error body when set true, or
+	// response error state with empty body by default.
+	ErrorBodyEnabled = false
+
+	// ErrorBodyDetail output the inner error detail in error body,
+	// keep it false on production to hide inner errors from client.
+	ErrorBodyDetail = false
+)
+
+// Default invar error codes of error states, it can be change by
+// RegisterErrorCode() or response custom code by ErrorCode().
+var errorCodes = map[int]int{
+	invar.E400ParseParams:      invar.WErrInvalidParams.Code,
+	invar.E401Unauthorized:     invar.WErrInvalidToken.Code,
+	invar.E403PermissionDenied: invar.WErrInvalidRole.Code,
+	invar.E404Exception:        invar.WErrCaseException.Code,
+	invar.E413TooLarge:         invar.WErrFileOverSize.Code,
+	invar.E415UnsupportedMedia: invar.WErrUnsupportFormat.Code,
+}
+
+// UseErrorBody enable structured error body, and set whether output error
+// details, the details default output on beego dev run mode.
+func UseErrorBody(detail ...bool) {
+	ErrorBodyEnabled = true
+	if len(detail) > 0 {
+		ErrorBodyDetail = detail[0]
+	} else {
+		ErrorBodyDetail = beego.BConfig.RunMode == beego.DEV
+	}
+}
+
+// RegisterErrorCode register the default invar error code of error state.
+func RegisterErrorCode(state int, werr *invar.WingErr) {
+	if werr != nil {
+		errorCodes[state] = werr.Code
+	}
+}
+
+// ErrorCode response error state with custom invar error code to client.
+func (c *WingController) ErrorCode(state int, werr *invar.WingErr, err ...string) {
+	body := c.newErrorBody(state, err...)
+	if werr != nil {
+		body.Code = werr.Code
+	}
+	c.responErrorBody(body)
+}
+
+// E400ValidateErr response 400 invalid params error state to client, the body
+// always contain field errors with messages translated by request language even
+// ErrorBodyEnabled is false, see TransLocale().
+func (c *WingController) E400ValidateErr(ps any, err error) {
+	c.Logger().E("Invalid input params:", ps)
+	body := c.newErrorBody(invar.E400ParseParams, err.Error())
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		body.Fields = ValidateMessages(err, c.TransLocale())
+		for _, fe := range verrs {
+			body.XFields = append(body.XFields, FieldError{
+				Field: fe.Field(), Tag: fe.Tag(), Message: body.Fields[fe.Field()],
+			})
+		}
+	}
+	c.responErrorBody(body)
+}
+
+// ----------------
+
+// newErrorBody create error body of state, and output error log.
+func (c *WingController) newErrorBody(state int, err ...string) *ErrorBody {
+	ctl, act := c.GetControllerAndAction()
+	errmsg := invar.StatusText(state)
+	body := &ErrorBody{Status: state, Code: errorCodes[state], Message: errmsg}
+	if len(err) > 0 {
+		errmsg += ", " + err[0]
+		if ErrorBodyDetail {
+			body.Detail = err[0]
+		}
+	}
+	c.Logger().E("Respone ERR:", state, ">", ctl+"."+act, errmsg)
+	return body
+}
+
+// responErrorBody response error body in the format which client accepted,
+// or only error state with empty body when ErrorBodyEnabled is false and the
+// body not contain field errors.
+func (c *WingController) responErrorBody(body *ErrorBody) {
+	writeErrorBody(c.Ctx, body)
+}
+
+// writeErrorBody write error body to client of context, it used by both
+// controllers and filters.
+func writeErrorBody(ctx *context.Context, body *ErrorBody) {
+	if !ErrorBodyEnabled && len(body.Fields) == 0 {
+		w := ctx.ResponseWriter
+		w.WriteHeader(body.Status)
+		w.Write([]byte(""))
+		return
+	}
+
+	out := ctx.Output
+	out.SetStatus(body.Status)
+	hasIndent := beego.BConfig.RunMode != beego.PROD
+	switch in := ctx.Input; {
+	case in.AcceptsXML():
+		out.XML(body, hasIndent)
+	case in.AcceptsYAML():
+		out.YAML(body)
+	default:
+		out.JSON(body, hasIndent, false)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"crypto/hmac"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/secure"
+	"github.com/wengoldx/wcore/utils"
+)
+
+// SchemeHMAC the AK/SK request signature auth scheme name.
+const SchemeHMAC = "HMAC-SHA256"
+
+// NonceStore the store to check request nonce whether used.
+type NonceStore interface {
+	// Use mark the nonce used in ttl duration, it return false when already used.
+	Use(nonce string, ttl time.Duration) (bool, error)
+}
+
+// memNonceStore the memory nonce store for single service instance.
+type memNonceStore struct {
+	lock   sync.Mutex
+	nonces map[string]int64 // expire unix time in nanoseconds, mapped by nonce
+}
+
+// redisNonceStore the redis nonce store for multiple service instances.
+type redisNonceStore struct {
+	conn   *WingRedisConn
+	prefix string
+}
+
+// HMACScheme the AK/SK request signature auth scheme for machine clients, such
+// as store and qk machines, the client sign requests by utils.SignRequestBy().
+//
+// `NOTICE` : it verify the request body hash, so you must set 'copyrequestbody = true'
+// in /conf/app.conf file. The body hash cover the decoded body when compressed, and
+// the multipart body or body over 'maxmemory' config will be rejected for beego not
+// copy them entirely, the body encoded by other than gzip will be rejected unless
+// decoded by UseBodyFilter().
+//
+// `USAGE` :
+//
+//	hmacs := &mvc.HMACScheme{
+//		Lookup: func(ak string) (string, *mvc.AuthClaims) {
+//			sk, uuid := service.GetMachineSecret(ak)
+//			return sk, &mvc.AuthClaims{Uuid: uuid, Role: invar.WRoleSMachine}
+//		},
+//		Nonces: mvc.NewRedisNonceStore(mvc.WingRedis, "nonce:"),
+//	}
+//	mvc.RegisterAuthSchemes("MachineController", hmacs)
+type HMACScheme struct {
+	// Lookup return the secret key and claims of access key, or empty secret
+	// key when access key invalid.
+	Lookup func(ak string) (string, *AuthClaims)
+
+	// MaxSkew the max time skew between client and server, default 5 minutes.
+	MaxSkew time.Duration
+
+	// Nonces the nonce store to prevent replay requests, default memory store.
+	Nonces NonceStore
+
+	once sync.Once
+}
+
+// NewMemNonceStore create memory nonce store, it only work for single service instance.
+func NewMemNonceStore() NonceStore {
+	return &memNonceStore{nonces: make(map[string]int64)}
+}
+
+// NewRedisNonceStore create redis nonce store by keys prefix.
+func NewRedisNonceStore(conn *WingRedisConn, prefix string) NonceStore {
+	return &redisNonceStore{conn: conn, prefix: prefix}
+}
+
+// Name return hmac scheme name.
+func (s *HMACScheme) Name() string { return SchemeHMAC }
+
+// Match check signature header exist.
+func (s *HMACScheme) Match(ctx *context.Context) bool {
+	return ctx.Input.Header(utils.HeaderSignature) != ""
+}
+
+// Auth verify request signature, timestamp skew and nonce.
+func (s *HMACScheme) Auth(ctx *context.Context) (*AuthClaims, int, error) {
+	s.once.Do(func() {
+		if s.MaxSkew <= 0 {
+			s.MaxSkew = 5 * time.Minute
+		}
+		if s.Nonces == nil {
+			s.Nonces = NewMemNonceStore()
+		}
+	})
+
+	in := ctx.Input
+	ak, signature := in.Header(utils.HeaderSignAccessKey), in.Header(utils.HeaderSignature)
+	timestamp, nonce := in.Header(utils.HeaderSignTimestamp), in.Header(utils.HeaderSignNonce)
+	if ak == "" || signature == "" || timestamp == "" || nonce == "" || s.Lookup == nil {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidSignature
+	}
+
+	// check timestamp skew
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidSignature
+	} else if skew := time.Since(time.Unix(ts, 0)); skew > s.MaxSkew || skew < -s.MaxSkew {
+		return nil, invar.E401Unauthorized, invar.ErrTokenExpired
+	}
+
+	sk, claims := s.Lookup(ak)
+	if sk == "" || claims == nil {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidAccount
+	}
+
+	// verify signature of canonical request
+	r := ctx.Request
+	if status, ok := signableBody(ctx); !ok {
+		return nil, status, invar.ErrInvalidSignature
+	}
+	canonical := utils.CanonicalRequest(r.Method, r.URL.EscapedPath(), r.URL.Query(), in.RequestBody, timestamp, nonce)
+	if !hmac.Equal([]byte(secure.SignSHA256(sk, canonical)), []byte(signature)) {
+		return nil, invar.E401Unauthorized, invar.ErrInvalidSignature
+	}
+
+	// check nonce after signature verified, the nonce must keep
+	// during the whole valid duration of timestamp
+	if fresh, err := s.Nonces.Use(ak+":"+nonce, 2*s.MaxSkew); err != nil {
+		return nil, invar.E401Unauthorized, err
+	} else if !fresh {
+		return nil, invar.E401Unauthorized, invar.ErrReplayRequest
+	}
+	return claims, invar.StatusOK, nil
+}
+
+// ----------------
+
+// signableBody check the request body whether copied entirely to verify signature,
+// it return 401 for multipart or uncopied body, 413 for body over MaxMemory, and
+// 415 for the encoded body which beego not decode.
+func signableBody(ctx *context.Context) (int, bool) {
+	r, in := ctx.Request, ctx.Input
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.ContentLength == 0 {
+		return invar.StatusOK, true
+	} else if in.IsUpload() || !beego.BConfig.CopyRequestBody {
+		return invar.E401Unauthorized, false
+	}
+
+	// beego only decode gzip body, the others decoded and the encoding
+	// header removed by body filter when UseBodyFilter() called
+	encoding := r.Header.Get("Content-Encoding")
+	if encoding != "" && encoding != "identity" && encoding != encodingGzip {
+		return invar.E415UnsupportedMedia, false
+	}
+
+	// the chunked or gzip decoded body maybe truncated when copied MaxMemory bytes
+	maxsize, copied := beego.BConfig.MaxMemory, int64(len(in.RequestBody))
+	truncatable := r.ContentLength < 0 || encoding == encodingGzip
+	if r.ContentLength > maxsize || copied > maxsize || (truncatable && copied == maxsize) {
+		return invar.E413TooLarge, false
+	}
+	return invar.StatusOK, true
+}
+
+// Use mark the nonce used in memory, and remove expired nonces.
+func (s *memNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now().UnixNano()
+	if expire, ok := s.nonces[nonce]; ok && expire > now {
+		return false, nil
+	}
+
+	// purge expired nonces when cached too many
+	if len(s.nonces) >= 10000 {
+		for key, expire := range s.nonces {
+			if expire <= now {
+				delete(s.nonces, key)
+			}
+		}
+	}
+	s.nonces[nonce] = now + int64(ttl)
+	return true, nil
+}
+
+// Use mark the nonce used by redis SET NX command.
+func (s *redisNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	key, expire := s.conn.NsKey(s.prefix+nonce), int64(ttl/time.Millisecond)
+	_, err := redis.String(con.Do("SET", key, 1, "NX", "PX", expire))
+	if err == redis.ErrNil {
+		return false, nil // already exist
+	}
+	return err == nil, err
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
+)
+
+// panicAlerter the DingTalk alerter of business function panics.
+type panicAlerter struct {
+	lock     sync.Mutex
+	sender   *utils.DTalkSender
+	interval time.Duration
+	lasts    map[string]time.Time // last alert time, mapped by controller action
+	drops    map[string]int       // dropped alerts count during interval
+}
+
+// The max stack length of alert message.
+const maxAlertStackLen = 3000
+
+// alerter the global panic alerter, nil to disable alert.
+var alerter *panicAlerter
+
+// SetPanicAlert set DingTalk sender to alert business function panics, the alerts
+// of same controller action will throttle in interval, and set nil sender to
+// disable alert.
+//
+// ---
+//
+//	sender := &utils.DTalkSender{WebHook: webhook, Keyword: "PANIC"}
+//	mvc.SetPanicAlert(sender, 5*time.Minute)
+func SetPanicAlert(sender *utils.DTalkSender, interval time.Duration) {
+	if sender == nil || sender.WebHook == "" {
+		alerter = nil
+		return
+	}
+
+	alerter = &panicAlerter{
+		sender: sender, interval: interval,
+		lasts: make(map[string]time.Time), drops: make(map[string]int),
+	}
+}
+
+// E500Panic response 500 internal server error state to client.
+func (c *WingController) E500Panic(err ...string) {
+	c.ErrorState(invar.E500InternalError, err...)
+}
+
+// ----------------
+
+// responNext execute business function with panic recovered, then response
+// the result, it response 500 error when business function panic.
+func (c *WingController) responNext(datatype string, protect, hidelog bool, next NextFunc) {
+	if status, resp, ok := c.safeNext(next); ok {
+		if resp != nil {
+			c.responCheckState(datatype, protect, hidelog, status, resp)
+		} else {
+			c.responCheckState(datatype, protect, hidelog, status)
+		}
+	}
+}
+
+// safeNext execute business function and recover panic, it log the stack
+// and response 500 error, then send alert if need.
+func (c *WingController) safeNext(next NextFunc) (status int, resp any, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r == beego.ErrAbort {
+				panic(r) // raised by StopRun() or Abort() to stop execution
+			}
+
+			ctl, act := c.GetControllerAndAction()
+			stack := string(debug.Stack())
+			c.Logger().E("Panic in", ctl+"."+act, "err:", r, "\n"+stack)
+
+			c.E500Panic(fmt.Sprint(r))
+			if alerter != nil {
+				alerter.alert(ctl+"."+act, c.RequestID(), fmt.Sprint(r), stack)
+			}
+			ok = false
+		}
+	}()
+
+	status, resp = next()
+	return status, resp, true
+}
+
+// alert send panic alert, the alerts of same action will throttle in interval.
+func (a *panicAlerter) alert(action, rid, err, stack string) {
+	a.lock.Lock()
+	now := time.Now()
+	if last, ok := a.lasts[action]; ok && now.Sub(last) < a.interval {
+		a.drops[action]++
+		a.lock.Unlock()
+		return
+	}
+
+	drops := a.drops[action]
+	a.lasts[action], a.drops[action] = now, 0
+	a.lock.Unlock()
+
+	if len(stack) > maxAlertStackLen {
+		stack = stack[:maxAlertStackLen] + "\n..."
+	}
+
+	lines := []string{
+		a.sender.Keyword, "Server: " + beego.BConfig.AppName,
+		"Action: " + action, "Request: " + rid, "Error: " + err,
+	}
+	if drops > 0 {
+		lines = append(lines, fmt.Sprintf("Dropped: %d alerts in %v", drops, a.interval))
+	}
+	content := strings.TrimSpace(strings.Join(append(lines, stack), "\n"))
+
+	sender := a.sender
+	go func() {
+		if err := sender.SendText(content, nil, nil, false, sender.Secure != ""); err != nil {
+			logger.E("Send panic alert, err:", err)
+		}
+	}()
+}
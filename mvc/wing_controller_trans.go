@@ -0,0 +1,182 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	"github.com/go-playground/locales/zh_Hant_TW"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_trans "github.com/go-playground/validator/v10/translations/en"
+	zh_trans "github.com/go-playground/validator/v10/translations/zh"
+	zhtw_trans "github.com/go-playground/validator/v10/translations/zh_tw"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// Supported locales of validate error messages, the en is fallback locale.
+const (
+	TransEN   = "en"         // English
+	TransZH   = "zh"         // Chinese Simplified
+	TransZHTW = "zh_Hant_TW" // Chinese Tradition
+)
+
+// The context data key of request language, set by SetLang().
+const ctxLangKey = "wing-lang"
+
+var (
+	// translator the universal translator of validate error messages.
+	translator *ut.UniversalTranslator
+
+	// transLock lock to generate translator and register translations.
+	transLock sync.Mutex
+
+	// customTrans the custom validator translations, mapped by tag and locale.
+	customTrans = map[string]map[string]string{}
+)
+
+// RegisterValidatorTrans register the validate error message translations of
+// custom validator, the trans mapped by locale such as TransZH, TransEN, and
+// the message can use {0} as field name, {1} as tag param.
+//
+// ---
+//
+//	mvc.RegisterFieldValidator("IsVaildUuid", isVaildUuid)
+//	mvc.RegisterValidatorTrans("IsVaildUuid", map[string]string{
+//		mvc.TransEN: "{0} must be a valid uuid",
+//		mvc.TransZH: "{0}必须是有效的UUID",
+//	})
+func RegisterValidatorTrans(tag string, trans map[string]string) {
+	transLock.Lock()
+	defer transLock.Unlock()
+
+	customTrans[tag] = trans
+	if translator != nil {
+		registerCustomTrans(tag, trans)
+	}
+}
+
+// ValidateMessages translate validate errors to field-message map by
+// given locale, it return empty map when err not validate errors.
+func ValidateMessages(err error, locale string) map[string]string {
+	msgs := make(map[string]string)
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		trans := getTranslator(locale)
+		for _, fe := range verrs {
+			msgs[fe.Field()] = fe.Translate(trans)
+		}
+	}
+	return msgs
+}
+
+// SetLang set the request language, it used to translate validate error
+// messages prior to Accept-Language header.
+func (c *WingController) SetLang(lang invar.Lang) {
+	c.Ctx.Input.SetData(ctxLangKey, lang)
+}
+
+// TransLocale return the translate locale of current request, it parse from
+// the language set by SetLang(), or Accept-Language header.
+func (c *WingController) TransLocale() string {
+	if lang, ok := c.Ctx.Input.GetData(ctxLangKey).(invar.Lang); ok {
+		if key := invar.GetLanguage(lang).Key; key != "" {
+			return parseTransLocale(key)
+		}
+	}
+
+	// parse Accept-Language header like 'zh-CN,zh;q=0.9,en;q=0.8'
+	for _, accept := range strings.Split(c.Ctx.Input.Header("Accept-Language"), ",") {
+		if key := strings.TrimSpace(strings.Split(accept, ";")[0]); key != "" {
+			return parseTransLocale(key)
+		}
+	}
+	return TransEN
+}
+
+// ----------------
+
+// parseTransLocale parse language key such as 'zh_CN', 'zh-TW' to translate locale.
+func parseTransLocale(key string) string {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	switch {
+	case key == "zh_tw", key == "zh_hk", key == "zh_mo", strings.HasPrefix(key, "zh_hant"):
+		return TransZHTW
+	case strings.HasPrefix(key, "zh"):
+		return TransZH
+	}
+	return TransEN
+}
+
+// getTranslator return the translator of locale, or fallback english translator.
+func getTranslator(locale string) ut.Translator {
+	ensureTranslatorGenerated()
+	trans, _ := translator.GetTranslator(locale)
+	return trans
+}
+
+// ensureTranslatorGenerated generate translator and register default translations
+// of en, zh and zh_tw locales onto Validator if need.
+func ensureTranslatorGenerated() {
+	transLock.Lock()
+	defer transLock.Unlock()
+	if translator != nil {
+		return
+	}
+
+	ensureValidatorGenerated()
+	translator = ut.New(en.New(), en.New(), zh.New(), zh_Hant_TW.New())
+	registers := map[string]func(*validator.Validate, ut.Translator) error{
+		TransEN:   en_trans.RegisterDefaultTranslations,
+		TransZH:   zh_trans.RegisterDefaultTranslations,
+		TransZHTW: zhtw_trans.RegisterDefaultTranslations,
+	}
+
+	for locale, register := range registers {
+		trans, _ := translator.GetTranslator(locale)
+		if err := register(Validator, trans); err != nil {
+			logger.E("Register", locale, "validator translations, err:", err)
+		}
+	}
+
+	for tag, trans := range customTrans {
+		registerCustomTrans(tag, trans)
+	}
+}
+
+// registerCustomTrans register custom validator translations onto Validator.
+func registerCustomTrans(tag string, trans map[string]string) {
+	for locale, text := range trans {
+		tr, found := translator.GetTranslator(locale)
+		if !found {
+			logger.W("Unsupported validator translation locale:", locale)
+			continue
+		}
+
+		err := Validator.RegisterTranslation(tag, tr, func(ut ut.Translator) error {
+			return ut.Add(tag, text, true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			msg, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		})
+		if err != nil {
+			logger.E("Register validator:"+tag, "translation, err:", err)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// FileRule the verify rule of upload files in one multipart form field.
+type FileRule struct {
+	Exts     []string // allowed file extensions such as '.jpg', ignore case, empty to allow all
+	Mimes    []string // allowed sniffed mime types or prefixs such as 'image/', empty to allow all
+	MaxSize  int64    // max bytes of each file, 0 for unlimited
+	MaxCount int      // max files count of field, 0 for unlimited
+	Required bool     // whether the field must contain at least one file
+}
+
+// UploadRules the file rules mapped by multipart form field name.
+type UploadRules map[string]*FileRule
+
+// UploadFiles the verified file headers mapped by multipart form field name.
+type UploadFiles map[string][]*multipart.FileHeader
+
+// UploadFunc do action after upload files verified.
+type UploadFunc func(files UploadFiles) (int, any)
+
+// UploadFunc2 do action after authed and upload files verified.
+type UploadFunc2 func(uuid string, files UploadFiles) (int, any)
+
+// Notice that beego parse multipart form by MaxMemory config before controllers
+// executed, so UploadMemory not work and UploadMaxBody only check Content-Length
+// header by default, call UseUploadFilter() to make them work for all requests.
+var (
+	// UploadMemory the max memory bytes to store multipart form files, the
+	// rest parts will store in temporary files, set 0 to use beego MaxMemory.
+	UploadMemory int64 = 0
+
+	// UploadMaxBody the max bytes of multipart request body, the request will
+	// response 413 error when over size, set 0 for unlimited.
+	UploadMaxBody int64 = 0
+)
+
+// Common file rules of images, audios and videos, same as utils.VerifyFile().
+var (
+	ImageRule = &FileRule{Exts: []string{".jpg", ".jpeg", ".png"}, Mimes: []string{"image/"}, MaxSize: 10 << 20}
+	AudioRule = &FileRule{Exts: []string{".mp3"}, Mimes: []string{"audio/"}, MaxSize: 10 << 20}
+	VideoRule = &FileRule{Exts: []string{".mp4"}, Mimes: []string{"video/"}, MaxSize: 500 << 20}
+)
+
+// DoAfterUploaded do bussiness action after success parse the multipart form,
+// bind and validate the text fields into ps, and verify the upload files by
+// given rules, the files of fields which not in rules will be ignored.
+//
+// Set ps as nil to skip bind text fields.
+//
+// ---
+//
+//	type AvatarParams struct {
+//		Name string `form:"name" validate:"required"`
+//	}
+//
+//	//	@router /avatar [post]
+//	func (c *AccController) Avatar() {
+//		ps := &types.AvatarParams{}
+//		rules := mvc.UploadRules{"avatar": mvc.ImageRule}
+//		c.DoAfterUploaded(ps, rules, func(files mvc.UploadFiles) (int, any) {
+//			fh := files.First("avatar")
+//			return http.StatusOK, service.SaveAvatar(ps.Name, fh)
+//		})
+//	}
+//
+//	@Return 400, 404, 413 codes returned on error.
+func (c *WingController) DoAfterUploaded(ps any, rules UploadRules, nextFunc UploadFunc, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	if files, ok := c.parseUploaded(ps, rules); ok {
+		c.responNext("json", protect, hidelog, func() (int, any) {
+			return nextFunc(files)
+		})
+	}
+}
+
+// DoAfterUploaded do bussiness action after success authed and verify upload files.
+//
+//	@Return 400, 401, 403, 404, 405, 413, 426 codes returned on error.
+func (c *WAuthController) DoAfterUploaded(ps any, rules UploadRules, nextFunc2 UploadFunc2, fs ...bool) {
+	protect, hidelog := !(len(fs) > 0 && !fs[0]), (len(fs) > 1 && fs[1])
+	if uuid, _ := c.innerAuthHeader(hidelog); uuid != "" {
+		if files, ok := c.parseUploaded(ps, rules); ok {
+			c.responNext("json", protect, hidelog, func() (int, any) {
+				return nextFunc2(uuid, files)
+			})
+		}
+	}
+}
+
+// UseUploadFilter parse multipart form before beego parse it, to store files
+// by UploadMemory and response 413 when request body over UploadMaxBody.
+//
+// ---
+//
+//	// call it before utils.HttpServer()
+//	mvc.UploadMaxBody = 100 << 20
+//	mvc.UseUploadFilter()
+//	utils.HttpServer()
+func UseUploadFilter() {
+	beego.InsertFilter("*", beego.BeforeStatic, func(ctx *context.Context) {
+		method := ctx.Request.Method
+		if method != http.MethodGet && method != http.MethodHead && ctx.Input.IsUpload() {
+			if status, err := parseMultipart(ctx); err != nil {
+				bodyErrorState(ctx, status, err.Error())
+			}
+		}
+	})
+	logger.I("Using multipart upload filter")
+}
+
+// First return the first file header of field, or nil when unexist.
+func (f UploadFiles) First(field string) *multipart.FileHeader {
+	if fhs := f[field]; len(fhs) > 0 {
+		return fhs[0]
+	}
+	return nil
+}
+
+// SaveUploadFile save the upload file to given file path, the parent
+// directories will auto create if unexist.
+func SaveUploadFile(fh *multipart.FileHeader, dst string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
+// Verify verify the file header by rule, and return http status when failed.
+func (r *FileRule) Verify(fh *multipart.FileHeader) (int, error) {
+	if r.MaxSize > 0 && fh.Size > r.MaxSize {
+		return invar.E413TooLarge, invar.ErrFileOverSize
+	}
+
+	if len(r.Exts) > 0 {
+		ext, matched := path.Ext(fh.Filename), false
+		for _, allow := range r.Exts {
+			if strings.EqualFold(ext, allow) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return invar.E400ParseParams, invar.ErrUnsupportedFile
+		}
+	}
+
+	if len(r.Mimes) > 0 {
+		mime, err := sniffMimeType(fh)
+		if err != nil {
+			return invar.E400ParseParams, err
+		}
+
+		for _, allow := range r.Mimes {
+			if strings.HasPrefix(mime, allow) {
+				return invar.StatusOK, nil
+			}
+		}
+		return invar.E400ParseParams, invar.ErrUnsupportedFile
+	}
+	return invar.StatusOK, nil
+}
+
+// ----------------
+
+// parseUploaded parse multipart form, bind text fields and verify files,
+// it will response error state to client when return false.
+func (c *WingController) parseUploaded(ps any, rules UploadRules) (UploadFiles, bool) {
+	r := c.Ctx.Request
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		c.E400Params("Not multipart form request")
+		return nil, false
+	}
+
+	if status, err := parseMultipart(c.Ctx); err != nil {
+		c.ErrorState(status, err.Error())
+		return nil, false
+	}
+
+	// bind and validate multipart text fields
+	if ps != nil {
+		if err := BindValues(ps, r.MultipartForm.Value, bindForm, c.Ctx.Input.Param); err != nil {
+			c.E400Params(err.Error())
+			return nil, false
+		}
+
+		ensureValidatorGenerated()
+		if err := Validator.Struct(ps); err != nil {
+			c.E400ValidateErr(ps, err)
+			return nil, false
+		}
+	}
+
+	// verify upload files of each field
+	files := UploadFiles{}
+	for field, rule := range rules {
+		fhs := r.MultipartForm.File[field]
+		if len(fhs) == 0 {
+			if rule.Required {
+				c.E400Params("Missing upload file: " + field)
+				return nil, false
+			}
+			continue
+		} else if rule.MaxCount > 0 && len(fhs) > rule.MaxCount {
+			c.E400Params("Too many files of " + field)
+			return nil, false
+		}
+
+		for _, fh := range fhs {
+			if status, err := rule.Verify(fh); err != nil {
+				c.Logger().E("Verify upload file", fh.Filename, "size:", fh.Size, "err:", err)
+				c.ErrorState(status, field+": "+err.Error())
+				return nil, false
+			}
+		}
+		files[field] = fhs
+	}
+	return files, true
+}
+
+// parseMultipart parse multipart form with UploadMaxBody and UploadMemory
+// limits, it only check Content-Length when the form parsed by beego.
+func parseMultipart(ctx *context.Context) (int, error) {
+	r := ctx.Request
+	if UploadMaxBody > 0 {
+		if r.ContentLength > UploadMaxBody {
+			return invar.E413TooLarge, errors.New("Request body over " + strconv.FormatInt(UploadMaxBody, 10) + " bytes")
+		} else if r.MultipartForm == nil {
+			r.Body = http.MaxBytesReader(ctx.ResponseWriter, r.Body, UploadMaxBody)
+		}
+	}
+
+	memory := UploadMemory
+	if memory <= 0 {
+		memory = beego.BConfig.MaxMemory
+	}
+
+	if err := r.ParseMultipartForm(memory); err != nil {
+		var maxerr *http.MaxBytesError
+		if errors.As(err, &maxerr) {
+			return invar.E413TooLarge, err
+		}
+		return invar.E400ParseParams, err
+	}
+	return invar.StatusOK, nil
+}
+
+// sniffMimeType detect the mime type of file content by the first 512 bytes.
+func sniffMimeType(fh *multipart.FileHeader) (string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
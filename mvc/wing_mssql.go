@@ -17,6 +17,7 @@ import (
 	"github.com/astaxie/beego"
 	"github.com/wengoldx/wcore/invar"
 	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
 	// ----------------------------------------
 	// NOTIC :
 	//
@@ -127,5 +128,7 @@ func OpenMssql(charset string) error {
 	con.SetMaxIdleConns(100)
 	con.SetMaxOpenConns(100)
 	MssqlHelper = &WingProvider{con}
+	utils.RegisterHealthCheck("mssql", con.PingContext)
+	utils.OnShutdown(utils.ShutdownStorage, "mssql", closeDB(con))
 	return nil
 }
@@ -11,6 +11,7 @@
 package mvc
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -19,6 +20,7 @@ import (
 	"github.com/astaxie/beego"
 	"github.com/wengoldx/wcore/invar"
 	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
 	// ----------------------------------------
 	// NOTIC :
 	//
@@ -119,6 +121,8 @@ func openMySQLPool(charset string, sessions []string) error {
 		con.SetMaxOpenConns(100)
 		con.SetConnMaxLifetime(28740)
 		connPool[session] = &WingProvider{con}
+		utils.RegisterHealthCheck("mysql:"+session, con.PingContext)
+		utils.OnShutdown(utils.ShutdownStorage, "mysql:"+session, closeDB(con))
 	}
 	return nil
 }
@@ -181,6 +185,11 @@ func OpenMySQL(charset string, sessions ...string) error {
 	return nil
 }
 
+// closeDB return the shutdown hook to close database connections.
+func closeDB(con *sql.DB) utils.ShutdownHook {
+	return func(ctx context.Context) error { return con.Close() }
+}
+
 // Select mysql Connection by request key words
 // if mode is dev, the key will auto splice '-dev'
 func Select(session string) *WingProvider {
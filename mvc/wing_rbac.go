@@ -0,0 +1,320 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
+)
+
+// RBACRule the access rule of subject to object and action.
+type RBACRule struct {
+	Sub string `json:"sub"` // role group key, role or account uuid, '*' for any subject
+	Obj string `json:"obj"` // router pattern, support '*', ':param' and '**' wildcards
+	Act string `json:"act"` // http methods separated by '|', '*' for any method
+	Eft string `json:"eft"` // rule effect, 'allow' or 'deny', default allow
+}
+
+// RBACPolicy the rbac policy datas, load from nacos config or file.
+//
+// ---
+//
+//	{
+//		"roles": {"admin": ["user"], "comp": ["user"]},
+//		"rules": [
+//			{"sub": "user",  "obj": "/v3/acc/*",      "act": "GET|POST"},
+//			{"sub": "admin", "obj": "/v3/admin/**",   "act": "*"},
+//			{"sub": "mach",  "obj": "/v3/acc/delete", "act": "*", "eft": "deny"}
+//		]
+//	}
+type RBACPolicy struct {
+	Roles map[string][]string `json:"roles"` // role inherit parents, mapped by role
+	Rules []*RBACRule         `json:"rules"` // access rules
+}
+
+// RBACEngine the local rbac policy engine to verify role access permission,
+// the deny rules take precedence over allow rules, and the role will be
+// transform to role group key by invar.GetRouterKey() before matching.
+//
+// `USAGE` :
+//
+//	engine := mvc.NewRBACEngine(func(uuid string) string {
+//		return service.GetRole(uuid)
+//	})
+//
+//	// load policy from nacos config and listen changes
+//	mc.ListenConfig(DID_RBAC_POLICY, engine.OnConfigChanged)
+//
+//	// use as global role handler
+//	mvc.GRoleHandlerFunc = engine.Handler()
+//
+//	// or use remote role handler with 60 seconds decision cache
+//	mvc.GRoleHandlerFunc = mvc.CachedRoleHandler(func(sub, obj, act string) bool {
+//		return wrpc.Singleton().AuthHeaderRole(context.Background(), sub, obj, act)
+//	}, 60*time.Second)
+type RBACEngine struct {
+	lock    sync.RWMutex
+	roles   map[string][]string // role inherit parents
+	rules   []*RBACRule         // access rules
+	roleOf  func(uuid string) string
+	onLoads []func()
+}
+
+// Effects of rbac rule.
+const (
+	RBACAllow = "allow"
+	RBACDeny  = "deny"
+)
+
+// NewRBACEngine create rbac engine, the roleOf return role of account uuid,
+// set nil to use the subject as role directly.
+func NewRBACEngine(roleOf func(uuid string) string) *RBACEngine {
+	return &RBACEngine{roleOf: roleOf, roles: map[string][]string{}}
+}
+
+// Load parse and replace the policy from json data.
+func (e *RBACEngine) Load(data string) error {
+	policy := &RBACPolicy{}
+	if err := json.Unmarshal([]byte(data), policy); err != nil {
+		return err
+	}
+
+	roles := make(map[string][]string)
+	for role, parents := range policy.Roles {
+		key := invar.GetRouterKey(role)
+		for _, parent := range parents {
+			roles[key] = append(roles[key], invar.GetRouterKey(parent))
+		}
+	}
+
+	rules := []*RBACRule{}
+	for _, rule := range policy.Rules {
+		if rule == nil || rule.Sub == "" || rule.Obj == "" {
+			continue
+		}
+
+		r := *rule
+		if r.Sub != "*" {
+			r.Sub = invar.GetRouterKey(r.Sub)
+		}
+		if r.Act == "" {
+			r.Act = "*"
+		}
+		r.Eft = strings.ToLower(r.Eft)
+		rules = append(rules, &r)
+	}
+
+	e.lock.Lock()
+	e.roles, e.rules = roles, rules
+	callbacks := e.onLoads
+	e.lock.Unlock()
+
+	for _, cb := range callbacks {
+		cb()
+	}
+	logger.I("Loaded rbac policy, roles:", len(roles), "rules:", len(rules))
+	return nil
+}
+
+// LoadFile load the policy from json file.
+func (e *RBACEngine) LoadFile(filepath string) error {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+	return e.Load(string(data))
+}
+
+// OnConfigChanged the nacos config callback to reload policy.
+func (e *RBACEngine) OnConfigChanged(dataId, data string) {
+	if err := e.Load(data); err != nil {
+		logger.E("Reload rbac policy", dataId, "err:", err)
+	}
+}
+
+// OnLoaded add callback called after policy loaded, such as clear decision cache.
+func (e *RBACEngine) OnLoaded(cb func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.onLoads = append(e.onLoads, cb)
+}
+
+// Enforce check whether the role can access the router by method.
+func (e *RBACEngine) Enforce(role, obj, act string) bool {
+	return e.enforce(obj, act, role)
+}
+
+// Handler return a RoleHandlerFunc to verify role by current engine, the
+// subject is account uuid or role when roleOf not set, and the rules of the
+// role, its inherited roles and the uuid evaluated together, so any matched
+// deny rule wins.
+func (e *RBACEngine) Handler() RoleHandlerFunc {
+	return func(sub, obj, act string) bool {
+		role := sub
+		if e.roleOf != nil {
+			if role = e.roleOf(sub); role == "" {
+				return false
+			}
+		}
+		return e.enforce(obj, act, role, sub)
+	}
+}
+
+// MatchRoute check the url path whether match router pattern, see utils.MatchRoute().
+func MatchRoute(pattern, url string) bool {
+	return utils.MatchRoute(pattern, url)
+}
+
+// ----------------
+
+// enforce check whether the subjects can access the router by method, the
+// rules of all subjects and their inherited roles evaluated once, it return
+// false when any deny rule matched.
+func (e *RBACEngine) enforce(obj, act string, subjects ...string) bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	subs := make(map[string]bool)
+	for _, subject := range subjects {
+		for sub := range e.inherits(invar.GetRouterKey(subject)) {
+			subs[sub] = true
+		}
+	}
+
+	allowed := false
+	for _, rule := range e.rules {
+		if !matchSubject(rule.Sub, subs) || !matchAction(rule.Act, act) || !MatchRoute(rule.Obj, obj) {
+			continue
+		}
+
+		if rule.Eft == RBACDeny {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
+// inherits return the role and all inherited parent roles.
+func (e *RBACEngine) inherits(role string) map[string]bool {
+	subs := map[string]bool{role: true}
+	queue := []string{role}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		for _, parent := range e.roles[r] {
+			if !subs[parent] {
+				subs[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return subs
+}
+
+// matchSubject check rule subject whether match any subject.
+func matchSubject(sub string, subs map[string]bool) bool {
+	return sub == "*" || subs[sub]
+}
+
+// matchAction check http method whether match rule actions.
+func matchAction(acts, act string) bool {
+	for _, a := range strings.Split(acts, "|") {
+		if a = strings.TrimSpace(a); a == "*" || strings.EqualFold(a, act) {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------
+
+// roleDecision the cached role decision.
+type roleDecision struct {
+	pass   bool
+	expire int64
+}
+
+// RoleCache the TTL decision cache in front of role handler.
+type RoleCache struct {
+	lock    sync.Mutex
+	handler RoleHandlerFunc
+	ttl     int64 // cache duration in nanoseconds
+	maxsize int   // max cached decisions
+	caches  map[string]*roleDecision
+}
+
+// NewRoleCache create role decision cache in front of given handler, such as
+// the handler call wrpc.Singleton().AuthHeaderRole, the maxsize default 10000.
+func NewRoleCache(handler RoleHandlerFunc, ttl time.Duration, maxsize ...int) *RoleCache {
+	size := 10000
+	if len(maxsize) > 0 && maxsize[0] > 0 {
+		size = maxsize[0]
+	}
+	return &RoleCache{
+		handler: handler, ttl: int64(ttl), maxsize: size,
+		caches: make(map[string]*roleDecision),
+	}
+}
+
+// CachedRoleHandler return role handler with TTL decision cache.
+func CachedRoleHandler(handler RoleHandlerFunc, ttl time.Duration, maxsize ...int) RoleHandlerFunc {
+	return NewRoleCache(handler, ttl, maxsize...).Handler
+}
+
+// Handler verify role by cached decision, or call the origin handler.
+func (rc *RoleCache) Handler(sub, obj, act string) bool {
+	key, now := sub+"\x00"+obj+"\x00"+act, time.Now().UnixNano()
+
+	rc.lock.Lock()
+	if d, ok := rc.caches[key]; ok && d.expire > now {
+		rc.lock.Unlock()
+		return d.pass
+	}
+	rc.lock.Unlock()
+
+	pass := rc.handler(sub, obj, act)
+
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	if len(rc.caches) >= rc.maxsize {
+		rc.purge(now)
+	}
+	rc.caches[key] = &roleDecision{pass: pass, expire: now + rc.ttl}
+	return pass
+}
+
+// Clear clear all cached decisions, call it when role policy changed.
+func (rc *RoleCache) Clear() {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	rc.caches = make(map[string]*roleDecision)
+}
+
+// purge remove expired decisions, or clear all when still full.
+func (rc *RoleCache) purge(now int64) {
+	for key, d := range rc.caches {
+		if d.expire <= now {
+			delete(rc.caches, key)
+		}
+	}
+
+	if len(rc.caches) >= rc.maxsize {
+		rc.caches = make(map[string]*roleDecision)
+	}
+}
@@ -11,14 +11,19 @@
 package mvc
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/astaxie/beego"
 	"github.com/gomodule/redigo/redis"
 	"github.com/wengoldx/wcore/invar"
 	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
 )
 
 // WingRedisConn content provider to support redis utils
@@ -44,13 +49,66 @@ type WingRedisConn struct {
 
 	// deadlock max duration, default 20 seconds
 	deadlockDuration int64
+
+	// redis connection options parsed from config file.
+	options *RedisOptions
+
+	// codec to serialize values for SetJSON, GetJSON helpers, default JSON.
+	codec CacheCodec
+}
+
+// RedisOptions redis connection options to dial standalone or sentinel
+// mode redis server, with optional ACL username, database index and TLS.
+//
+// `NOTICE` :
+//
+// The cluster mode not support by current redis connections pool.
+type RedisOptions struct {
+	Mode        string   // connection mode, RedisStandalone or RedisSentinel
+	Host        string   // redis server host and port of standalone mode
+	User        string   // ACL username since redis 6.0, optional
+	Password    string   // redis server password, skip AUTH when empty
+	DB          int      // database index, default 0
+	Sentinels   []string // sentinel servers host and port of sentinel mode
+	Master      string   // master name monitored by sentinels
+	SentinelPwd string   // sentinel servers password, optional
+	TLS         bool     // whether connect redis server with TLS
+	CAFile      string   // CA cert file to verify server, optional
+	CertFile    string   // client certificate file for mutual TLS, optional
+	KeyFile     string   // client secure key file for mutual TLS, optional
+	SkipVerify  bool     // whether skip verify server certificate
+	MaxIdle     int      // max idle connections in pool, default 16
+	MaxActive   int      // max active connections in pool, 0 is unlimited
+	IdleTimeout int64    // idle connection timeout in seconds, default 300
+	Wait        bool     // whether wait idle connection when pool full
 }
 
 const (
-	redisConfigHost = "%s::host"      // configs key of redis host and port
-	redisConfigPwd  = "%s::pwd"       // configs key of redis password
-	redisConfigNs   = "%s::namespace" // configs key of redis namespace
-	redisConfigLock = "%s::deadlock"  // configs key of redis lock max duration
+	redisConfigHost   = "%s::host"        // configs key of redis host and port
+	redisConfigUser   = "%s::user"        // configs key of redis ACL username
+	redisConfigPwd    = "%s::pwd"         // configs key of redis password
+	redisConfigDB     = "%s::db"          // configs key of redis database index
+	redisConfigNs     = "%s::namespace"   // configs key of redis namespace
+	redisConfigLock   = "%s::deadlock"    // configs key of redis lock max duration
+	redisConfigMode   = "%s::mode"        // configs key of redis connection mode
+	redisConfigSnls   = "%s::sentinels"   // configs key of sentinel servers
+	redisConfigMaster = "%s::master"      // configs key of sentinel master name
+	redisConfigSnlPwd = "%s::sentinelpwd" // configs key of sentinel password
+	redisConfigTLS    = "%s::tls"         // configs key of TLS enable flag
+	redisConfigCA     = "%s::cafile"      // configs key of TLS CA file
+	redisConfigCert   = "%s::certfile"    // configs key of TLS cert file
+	redisConfigKey    = "%s::keyfile"     // configs key of TLS key file
+	redisConfigSkip   = "%s::skipverify"  // configs key of TLS skip verify flag
+	redisConfigIdle   = "%s::maxidle"     // configs key of pool max idle
+	redisConfigActive = "%s::maxactive"   // configs key of pool max active
+	redisConfigIdleTo = "%s::idletimeout" // configs key of pool idle timeout
+	redisConfigWait   = "%s::wait"        // configs key of pool wait flag
+)
+
+// Redis connection modes
+const (
+	RedisStandalone = "standalone" // connect single redis server
+	RedisSentinel   = "sentinel"   // discover master from sentinel servers
 )
 
 // The follow options may support by diffrent Redis version, get more info
@@ -76,20 +134,47 @@ const (
 var WingRedis *WingRedisConn
 
 // readRedisCofnigs read redis params from config file, than verify them if empty.
-func readRedisCofnigs(session string) (string, string, string, int64, error) {
-	host := beego.AppConfig.String(fmt.Sprintf(redisConfigHost, session))
-	pwd := beego.AppConfig.String(fmt.Sprintf(redisConfigPwd, session))
-	ns := beego.AppConfig.String(fmt.Sprintf(redisConfigNs, session)) // allow empty
-	lock := beego.AppConfig.DefaultInt64(fmt.Sprintf(redisConfigLock, session), 20)
+func readRedisCofnigs(session string) (*RedisOptions, string, int64, error) {
+	key := func(format string) string { return fmt.Sprintf(format, session) }
+	opts := &RedisOptions{
+		Mode:        beego.AppConfig.DefaultString(key(redisConfigMode), RedisStandalone),
+		Host:        beego.AppConfig.String(key(redisConfigHost)),
+		User:        beego.AppConfig.String(key(redisConfigUser)),
+		Password:    beego.AppConfig.String(key(redisConfigPwd)),
+		DB:          beego.AppConfig.DefaultInt(key(redisConfigDB), 0),
+		Sentinels:   beego.AppConfig.Strings(key(redisConfigSnls)),
+		Master:      beego.AppConfig.String(key(redisConfigMaster)),
+		SentinelPwd: beego.AppConfig.String(key(redisConfigSnlPwd)),
+		TLS:         beego.AppConfig.DefaultBool(key(redisConfigTLS), false),
+		CAFile:      beego.AppConfig.String(key(redisConfigCA)),
+		CertFile:    beego.AppConfig.String(key(redisConfigCert)),
+		KeyFile:     beego.AppConfig.String(key(redisConfigKey)),
+		SkipVerify:  beego.AppConfig.DefaultBool(key(redisConfigSkip), false),
+		MaxIdle:     beego.AppConfig.DefaultInt(key(redisConfigIdle), 16),
+		MaxActive:   beego.AppConfig.DefaultInt(key(redisConfigActive), 0),
+		IdleTimeout: beego.AppConfig.DefaultInt64(key(redisConfigIdleTo), 300),
+		Wait:        beego.AppConfig.DefaultBool(key(redisConfigWait), false),
+	}
+	ns := beego.AppConfig.String(key(redisConfigNs)) // allow empty
+	lock := beego.AppConfig.DefaultInt64(key(redisConfigLock), 20)
 
-	if host == "" || pwd == "" {
-		return "", "", "", 0, invar.ErrInvalidConfigs
+	switch opts.Mode {
+	case RedisStandalone:
+		if opts.Host == "" {
+			return nil, "", 0, invar.ErrInvalidConfigs
+		}
+	case RedisSentinel:
+		if len(opts.Sentinels) == 0 || opts.Master == "" {
+			return nil, "", 0, invar.ErrInvalidConfigs
+		}
+	default:
+		return nil, "", 0, invar.ErrInvalidConfigs
 	}
 
 	if lock <= 0 {
 		lock = 20 // default 20 seconds
 	}
-	return host, pwd, ns, lock, nil
+	return opts, ns, lock, nil
 }
 
 // OpenRedis connect redis database server and auth password,
@@ -125,52 +210,104 @@ func readRedisCofnigs(session string) (string, string, string, int64, error) {
 //	host = "127.0.0.1:6379"
 //	pwd = "123456"
 //
+// #### Case 5 : For connect with ACL user, database index, TLS and pool sizes.
+//
+//	[redis]
+//	host = "127.0.0.1:6379"
+//	user = "default"
+//	pwd = "123456"
+//	db = 2
+//	tls = true
+//	cafile = "./certs/redis-ca.pem"
+//	maxidle = 16
+//	maxactive = 64
+//	idletimeout = 300
+//
+// #### Case 6 : For connect master discovered from sentinels.
+//
+//	[redis]
+//	mode = "sentinel"
+//	sentinels = "10.0.0.1:26379;10.0.0.2:26379;10.0.0.3:26379"
+//	master = "mymaster"
+//	pwd = "123456"
+//
 // ---
 //
 // The configs means as:
 //
 //	`host` - is the redis server host ip and port.
-//	`pwd`  - is the redis server authenticate password.
+//	`pwd`  - is the redis server authenticate password, skip auth when empty.
 //	`namespace` - is the prefix string or store key.
 //	`deadlock`  - is the max time of deadlock, in seconds.
+//	`user` - is the ACL username since redis 6.0, optional.
+//	`db`   - is the database index, default 0.
+//	`mode` - is the connection mode of 'standalone' or 'sentinel', default 'standalone'.
+//	`sentinels`   - is the sentinel servers host and port split by ';'.
+//	`master`      - is the master name monitored by sentinels.
+//	`sentinelpwd` - is the sentinel servers password, optional.
+//	`tls` - is whether connect with TLS, and cafile, certfile, keyfile, skipverify for TLS.
+//	`maxidle`, `maxactive`, `idletimeout`, `wait` - is the connections pool configs.
 func OpenRedis() error {
 	session := "redis"
 	if beego.BConfig.RunMode == "dev" {
 		session = session + "-dev"
 	}
 
-	host, pwd, ns, lock, err := readRedisCofnigs(session)
+	opts, ns, lock, err := readRedisCofnigs(session)
 	if err != nil {
 		return err
 	}
+	return OpenRedisWith(opts, ns, lock)
+}
 
-	WingRedis = &WingRedisConn{nil, host, pwd, ns, lock}
-	WingRedis.redisPool = &redis.Pool{
-		MaxIdle: 16, MaxActive: 0, IdleTimeout: 300 * time.Second,
-		Dial: func() (redis.Conn, error) {
-			host, pwd := WingRedis.serverHost, WingRedis.serverAuthPwd
-			c, err := redis.Dial("tcp", host) // dial TCP connection
-			if err != nil {
-				return nil, err
-			}
+// OpenRedisWith connect redis database server by given options, the
+// connections holded by mvc.WingRedis object.
+func OpenRedisWith(opts *RedisOptions, ns string, lock int64) error {
+	if opts.Mode == "" {
+		opts.Mode = RedisStandalone
+	}
 
-			// authenticate connection password. see https://redis.io/commands/auth
-			if _, err := c.Do("AUTH", pwd); err != nil {
-				c.Close()
-				panic(err)
-			}
-			return c, nil
+	tlsconf, err := genRedisTLSConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	conn := &WingRedisConn{
+		serverHost: opts.Host, serverAuthPwd: opts.Password,
+		serviceNamespace: ns, deadlockDuration: lock, options: opts,
+	}
+	conn.redisPool = &redis.Pool{
+		MaxIdle: opts.MaxIdle, MaxActive: opts.MaxActive, Wait: opts.Wait,
+		IdleTimeout: time.Duration(opts.IdleTimeout) * time.Second,
+		Dial: func() (redis.Conn, error) {
+			return conn.dial(tlsconf)
 		},
 		TestOnBorrow: func(c redis.Conn, t time.Time) error {
-			if _, err := c.Do("PING"); err != nil {
+			if conn.options.Mode == RedisSentinel {
+				return checkRedisMaster(c) // drop the old master connections after failover
+			} else if _, err := c.Do("PING"); err != nil {
 				return errors.New("Ping redis err: " + err.Error())
 			}
 			return nil
 		},
 	}
+	WingRedis = conn
+	utils.RegisterHealthCheck("redis", conn.Ping)
+	utils.OnShutdown(utils.ShutdownStorage, "redis", func(ctx context.Context) error {
+		return conn.redisPool.Close()
+	})
 	return nil
 }
 
+// Ping check redis server connectivity by PING command.
+func (c *WingRedisConn) Ping(ctx context.Context) error {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	_, err := con.Do("PING")
+	return err
+}
+
 // SetNamespace set server uniqu namespace
 func (c *WingRedisConn) SetNamespace(ns string) {
 	if ns != "" {
@@ -178,6 +315,22 @@ func (c *WingRedisConn) SetNamespace(ns string) {
 	}
 }
 
+// SetCodec set the codec to serialize values for SetJSON, GetJSON helpers,
+// such as JSONCodec, GzipJSONCodec or MsgpackCodec.
+func (c *WingRedisConn) SetCodec(codec CacheCodec) {
+	if codec != nil {
+		c.codec = codec
+	}
+}
+
+// GetCodec get the codec to serialize values, default JSONCodec.
+func (c *WingRedisConn) GetCodec() CacheCodec {
+	if c.codec == nil {
+		return JSONCodec
+	}
+	return c.codec
+}
+
 // SetDeadlock set the max deadlock duration
 func (c *WingRedisConn) SetDeadlock(dur int64) {
 	if dur > 0 {
@@ -273,7 +426,7 @@ func (c *WingRedisConn) getWithOptions(key string, options ...any) (any, error)
 	if options != nil {
 		var reply any
 		err := invar.ErrInvalidRedisOptions
-		if option, expire := c.parseGetOptions(options); option != "" {
+		if option, expire := c.parseGetOptions(options...); option != "" {
 			switch option {
 			case CusOptDel:
 				reply, err = con.Do("GETDEL", c.serviceNamespace+key)
@@ -331,6 +484,132 @@ func (c *WingRedisConn) setKeyExpire(key, commond string, expire int64, option .
 	return set
 }
 
+// dial connect redis server or master discovered from sentinels, then
+// authenticate password and select database index.
+func (c *WingRedisConn) dial(tlsconf *tls.Config) (redis.Conn, error) {
+	opts, host := c.options, c.serverHost
+	if opts.Mode == RedisSentinel {
+		master, err := c.discoverMaster()
+		if err != nil {
+			return nil, err
+		}
+		host = master
+	}
+
+	dialopts := []redis.DialOption{redis.DialConnectTimeout(10 * time.Second)}
+	if tlsconf != nil {
+		dialopts = append(dialopts, redis.DialUseTLS(true), redis.DialTLSConfig(tlsconf))
+	}
+
+	con, err := redis.Dial("tcp", host, dialopts...) // dial TCP connection
+	if err != nil {
+		return nil, err
+	}
+
+	// authenticate connection password. see https://redis.io/commands/auth
+	if err := authRedisConn(con, opts.User, opts.Password); err != nil {
+		con.Close()
+		return nil, err
+	}
+
+	if opts.DB > 0 {
+		if _, err := con.Do("SELECT", opts.DB); err != nil {
+			con.Close()
+			return nil, err
+		}
+	}
+	return con, nil
+}
+
+// discoverMaster query master address from sentinel servers one by one,
+// it return the first success queried master host and port.
+//
+// see [sentinel](https://redis.io/docs/management/sentinel)
+func (c *WingRedisConn) discoverMaster() (string, error) {
+	opts := c.options
+	for _, sentinel := range opts.Sentinels {
+		con, err := redis.Dial("tcp", sentinel, redis.DialConnectTimeout(3*time.Second))
+		if err != nil {
+			logger.W("Dial redis sentinel", sentinel, "err:", err)
+			continue
+		}
+
+		if err := authRedisConn(con, "", opts.SentinelPwd); err != nil {
+			logger.W("Auth redis sentinel", sentinel, "err:", err)
+			con.Close()
+			continue
+		}
+
+		addr, err := redis.Strings(con.Do("SENTINEL", "get-master-addr-by-name", opts.Master))
+		con.Close()
+		if err != nil || len(addr) != 2 {
+			logger.W("Query master from sentinel", sentinel, "err:", err)
+			continue
+		}
+		return addr[0] + ":" + addr[1], nil
+	}
+	return "", errors.New("Not found redis master " + opts.Master)
+}
+
+// authRedisConn authenticate connection by ACL username and password,
+// it will skip authenticate when password is empty.
+func authRedisConn(con redis.Conn, user, pwd string) error {
+	if pwd == "" {
+		return nil
+	} else if user != "" {
+		_, err := con.Do("AUTH", user, pwd)
+		return err
+	}
+	_, err := con.Do("AUTH", pwd)
+	return err
+}
+
+// checkRedisMaster check the connected redis server whether master role.
+//
+// see command [role](https://redis.io/commands/role)
+func checkRedisMaster(con redis.Conn) error {
+	role, err := redis.Values(con.Do("ROLE"))
+	if err != nil || len(role) == 0 {
+		return errors.New("Check redis role failed")
+	}
+
+	if kind, _ := redis.String(role[0], nil); kind != "master" {
+		return errors.New("Redis role changed to " + kind)
+	}
+	return nil
+}
+
+// genRedisTLSConfig generate TLS config from CA and certificate files,
+// it return nil config when TLS disabled.
+func genRedisTLSConfig(opts *RedisOptions) (*tls.Config, error) {
+	if !opts.TLS {
+		return nil, nil
+	}
+
+	tlsconf := &tls.Config{InsecureSkipVerify: opts.SkipVerify}
+	if opts.CAFile != "" {
+		ca, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, invar.ErrBadPublicKey
+		}
+		tlsconf.RootCAs = pool
+	}
+
+	if opts.CertFile != "" && opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsconf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsconf, nil
+}
+
 // GetRedisPool get redis pool
 func (c *WingRedisConn) GetRedisPool() redis.Conn {
 	return c.redisPool.Get()
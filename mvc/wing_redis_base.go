@@ -138,15 +138,14 @@ func (c *WingRedisConn) Exist(key string) (bool, error) {
 }
 
 // Exists determine if given keys exist, and return exist keys count,
-// you can use NsKey() or Nskeys() to transform origin keys to namespaced keys,
-// and then call Exists(nskeys[0], nskeys[1] ... nskeys[m]) to multiple check.
+// the origin keys will transform to namespaced keys as other methods.
 //
 // see https://redis.io/commands/exists
-func (c *WingRedisConn) Exists(nskeys ...string) (int, error) {
+func (c *WingRedisConn) Exists(keys ...string) (int, error) {
 	con := c.redisPool.Get()
 	defer con.Close()
 
-	return redis.Int(con.Do("EXISTS", nskeys))
+	return redis.Int(con.Do("EXISTS", redis.Args{}.AddFlat(c.NsArrKeys(keys))...))
 }
 
 // Expire set a key's time to live in seconds, the optional values can be set
@@ -199,16 +198,15 @@ func (c *WingRedisConn) Delete(key string) bool {
 	return deleted
 }
 
-// Deletes delete the given keys, and return deleted keys count.
-// you can use NsKey() or Nskeys() to transform origin keys to namespaced keys,
-// and then call Deletes(nskeys[0], nskeys[1] ... nskeys[m]) to multiple delete.
+// Deletes delete the given keys, and return deleted keys count,
+// the origin keys will transform to namespaced keys as other methods.
 //
 // see https://redis.io/commands/del
-func (c *WingRedisConn) Deletes(nskeys ...string) (int, error) {
+func (c *WingRedisConn) Deletes(keys ...string) (int, error) {
 	con := c.redisPool.Get()
 	defer con.Close()
 
-	return redis.Int(con.Do("DEL", nskeys))
+	return redis.Int(con.Do("DEL", redis.Args{}.AddFlat(c.NsArrKeys(keys))...))
 }
 
 // GetRange get the string value of key cut by given range.
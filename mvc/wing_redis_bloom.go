@@ -0,0 +1,143 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// BloomFilter a bloom filter base on redis bitmap, it use to check elements
+// membership with less memory than sets, the exist result maybe false positive
+// on the configured error rate, but the unexist result always correct.
+//
+// `USAGE` :
+//
+//	// check coupon notice whether already sent
+//	bf := mvc.NewBloomFilter(mvc.WingRedis, "bloom:notice:"+cid, 1000000, 0.001)
+//	if sent, _ := bf.Exist(uuid); !sent {
+//		// send notice ...
+//		bf.Add(uuid)
+//	}
+type BloomFilter struct {
+	conn   *WingRedisConn // redis connection to store bitmap
+	key    string         // bitmap key, the namespace will auto append
+	bits   uint64         // bitmap size in bits
+	hashes uint64         // hash functions count
+}
+
+// Max bitmap size of redis string value, 512MB
+const bloomMaxBits = uint64(1) << 32
+
+// NewBloomFilter create a bloom filter by expected elements capacity and false
+// positive error rate, the bitmap size and hash functions count calculated as:
+//
+//	bits   = -capacity * ln(errrate) / (ln2)^2
+//	hashes = bits / capacity * ln2
+func NewBloomFilter(conn *WingRedisConn, key string, capacity uint64, errrate float64) *BloomFilter {
+	if capacity == 0 {
+		capacity = 1
+	}
+	if errrate <= 0 || errrate >= 1 {
+		errrate = 0.01
+	}
+
+	n := float64(capacity)
+	bits := uint64(math.Ceil(-n * math.Log(errrate) / (math.Ln2 * math.Ln2)))
+	if bits > bloomMaxBits {
+		bits = bloomMaxBits
+	}
+
+	hashes := uint64(math.Round(float64(bits) / n * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &BloomFilter{conn: conn, key: key, bits: bits, hashes: hashes}
+}
+
+// Add add element into bloom filter.
+func (b *BloomFilter) Add(element string) error {
+	return b.Adds(element)
+}
+
+// Adds add elements into bloom filter in one pipeline.
+func (b *BloomFilter) Adds(elements ...string) error {
+	con := b.conn.redisPool.Get()
+	defer con.Close()
+
+	nskey := b.conn.NsKey(b.key)
+	for _, element := range elements {
+		for _, offset := range b.offsets(element) {
+			if err := con.Send("SETBIT", nskey, offset, 1); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := con.Do("")
+	return err
+}
+
+// Exist check the element whether exist in bloom filter, it maybe return
+// true for unexist element on the configured error rate.
+func (b *BloomFilter) Exist(element string) (bool, error) {
+	con := b.conn.redisPool.Get()
+	defer con.Close()
+
+	nskey, offsets := b.conn.NsKey(b.key), b.offsets(element)
+	for _, offset := range offsets {
+		if err := con.Send("GETBIT", nskey, offset); err != nil {
+			return false, err
+		}
+	}
+
+	bits, err := redis.Ints(con.Do(""))
+	if err != nil {
+		return false, err
+	}
+
+	for _, bit := range bits {
+		if bit == 0 {
+			return false, nil
+		}
+	}
+	return len(bits) == len(offsets), nil
+}
+
+// Clear delete the bloom filter bitmap.
+func (b *BloomFilter) Clear() bool {
+	return b.conn.Delete(b.key)
+}
+
+// Expire set the bloom filter bitmap time to live in seconds.
+func (b *BloomFilter) Expire(expire int64) bool {
+	return b.conn.Expire(b.key, expire)
+}
+
+// Bits return the bitmap size and hash functions count.
+func (b *BloomFilter) Bits() (uint64, uint64) {
+	return b.bits, b.hashes
+}
+
+// offsets return the bit offsets of element by double hashing.
+func (b *BloomFilter) offsets(element string) []uint64 {
+	h1, h2 := fnv.New64a(), fnv.New64()
+	h1.Write([]byte(element))
+	h2.Write([]byte(element))
+
+	s1, s2 := h1.Sum64(), h2.Sum64()|1 // keep the step odd
+	offsets := make([]uint64, b.hashes)
+	for i := uint64(0); i < b.hashes; i++ {
+		offsets[i] = (s1 + i*s2) % b.bits
+	}
+	return offsets
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheLoader load the original value from database when cache missed.
+type CacheLoader[T any] func() (T, error)
+
+// WingCache a typed cache-aside helper base on WingRedisConn, it read value
+// from redis first, and load it from database by given loader when missed,
+// then cache the loaded value with a random jitter expiration.
+//
+// `USAGE` :
+//
+//	// cache account profile for 10 minutes, jitter max 60 seconds
+//	cache := mvc.NewCache[types.Profile](mvc.WingRedis, "profile:", 600)
+//	cache.SetJitter(60)
+//
+//	profile, err := cache.GetOrLoad(uuid, func() (types.Profile, error) {
+//		return models.GetProfile(uuid) // return invar.ErrNotFound when unexist
+//	}, "acc:"+uuid /* tags for invalidate */)
+//
+//	// invalidate all cached values of the tag
+//	cache.InvalidateTags("acc:" + uuid)
+type WingCache[T any] struct {
+	conn     *WingRedisConn     // redis connection to cache datas
+	prefix   string             // cache keys prefix, the namespace will auto append
+	codec    CacheCodec         // codec to serialize cache value, default JSON
+	expire   int64              // cache value expiration in seconds
+	jitter   int64              // max random expiration jitter in seconds
+	negative int64              // expiration in seconds to cache unexist results
+	flights  singleflight.Group // collapse concurrent loads of the same key
+}
+
+const (
+	cacheTagPrefix = "cache:tag:" // keys prefix of cache tag sets
+	cacheNegative  = "\x00<nil>"  // placeholder value of unexist results
+	cacheDefNeg    = 30           // default expiration of unexist results
+)
+
+// Lua script to bind cache key into tag set, and extend the tag set expiration
+// to make sure it not expired before the cache key.
+//
+//	KEYS[1] : set key of cache tag
+//	ARGV[1] : namespaced cache key
+//	ARGV[2] : cache key expiration in seconds
+const cacheTagScript = `
+redis.call('SADD', KEYS[1], ARGV[1])
+if redis.call('TTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return 1`
+
+// Lua script to delete tag set and the cache keys bind with it atomically,
+// the keys deleted in chunks to not over the lua unpack stack limit.
+//
+//	KEYS[1] : set key of cache tag
+const cacheInvalidateScript = `
+local keys = redis.call('SMEMBERS', KEYS[1])
+for i = 1, #keys, 1000 do
+	redis.call('DEL', unpack(keys, i, math.min(i + 999, #keys)))
+end
+redis.call('DEL', KEYS[1])
+return #keys`
+
+var (
+	cacheTag        = redis.NewScript(1, cacheTagScript)
+	cacheInvalidate = redis.NewScript(1, cacheInvalidateScript)
+)
+
+// NewCache create a typed cache helper on given redis connection, the expire
+// is cache value expiration in seconds, and cache keys join prefix as 'prefix+key'.
+func NewCache[T any](conn *WingRedisConn, prefix string, expire int64) *WingCache[T] {
+	return &WingCache[T]{
+		conn: conn, prefix: prefix, codec: JSONCodec,
+		expire: expire, negative: cacheDefNeg,
+	}
+}
+
+// SetCodec set the custom codec to serialize cache values.
+func (c *WingCache[T]) SetCodec(codec CacheCodec) {
+	if codec != nil {
+		c.codec = codec
+	}
+}
+
+// SetJitter set the max random jitter in seconds append to expiration,
+// it use to avoid cache values expired on the same time.
+func (c *WingCache[T]) SetJitter(jitter int64) {
+	if jitter >= 0 {
+		c.jitter = jitter
+	}
+}
+
+// SetNegative set the expiration in seconds to cache unexist results,
+// set 0 to disable cache unexist results.
+func (c *WingCache[T]) SetNegative(expire int64) {
+	if expire >= 0 {
+		c.negative = expire
+	}
+}
+
+// Get get the cached value of key, it return invar.ErrNotFound when
+// the key unexist or cached as unexist result.
+func (c *WingCache[T]) Get(key string) (T, error) {
+	out, _, err := c.get(key)
+	if err == redis.ErrNil {
+		return out, invar.ErrNotFound
+	}
+	return out, err
+}
+
+// Set cache the value of key with jitter expiration, and bind the key with given tags.
+func (c *WingCache[T]) Set(key string, value T, tags ...string) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.setRaw(key, data, c.expire+c.randJitter(), tags...)
+}
+
+// GetOrLoad get the cached value of key, or load it by given loader when missed.
+//
+// The concurrent misses of the same key will be collapsed into one load, the loaded
+// value will cached with jitter expiration, and if loader return invar.ErrNotFound
+// or invar.ErrNoneRowFound error, the unexist result will cached as a short time,
+// and both the load and cached unexist result return invar.ErrNotFound error.
+func (c *WingCache[T]) GetOrLoad(key string, loader CacheLoader[T], tags ...string) (T, error) {
+	out, negative, err := c.get(key)
+	if err == nil {
+		return out, nil
+	} else if negative {
+		return out, invar.ErrNotFound
+	} else if err != redis.ErrNil {
+		logger.W("Get cache [key:"+key+"] err:", err)
+	}
+
+	val, err, _ := c.flights.Do(key, func() (any, error) {
+		value, err := loader()
+		if err == invar.ErrNotFound || err == invar.ErrNoneRowFound {
+			if c.negative > 0 {
+				c.setRaw(key, []byte(cacheNegative), c.negative, tags...)
+			}
+			return value, invar.ErrNotFound
+		} else if err != nil {
+			return value, err
+		}
+
+		if err := c.Set(key, value, tags...); err != nil {
+			logger.W("Set cache [key:"+key+"] err:", err)
+		}
+		return value, nil
+	})
+
+	if v, ok := val.(T); ok {
+		out = v
+	}
+	return out, err
+}
+
+// Invalidate delete the cached values of given keys.
+func (c *WingCache[T]) Invalidate(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	con := c.conn.redisPool.Get()
+	defer con.Close()
+
+	args := []any{}
+	for _, key := range keys {
+		args = append(args, c.nsKey(key))
+	}
+	_, err := con.Do("DEL", args...)
+	return err
+}
+
+// InvalidateTags delete all the cached values bind with given tags.
+func (c *WingCache[T]) InvalidateTags(tags ...string) error {
+	con := c.conn.redisPool.Get()
+	defer con.Close()
+
+	for _, tag := range tags {
+		tagkey := c.conn.NsKey(cacheTagPrefix + tag)
+		if _, err := cacheInvalidate.Do(con, tagkey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ----------------
+
+// nsKey return the namespaced cache key.
+func (c *WingCache[T]) nsKey(key string) string {
+	return c.conn.NsKey(c.prefix + key)
+}
+
+// randJitter return a random jitter expiration in seconds.
+func (c *WingCache[T]) randJitter() int64 {
+	if c.jitter > 0 {
+		return rand.Int63n(c.jitter + 1)
+	}
+	return 0
+}
+
+// get get and unmarshal the cached value of key, it return redis.ErrNil error
+// when the key unexist, or return true and invar.ErrNotFound error when the key
+// cached as unexist result.
+func (c *WingCache[T]) get(key string) (T, bool, error) {
+	var out T
+	con := c.conn.redisPool.Get()
+	defer con.Close()
+
+	data, err := redis.Bytes(con.Do("GET", c.nsKey(key)))
+	if err != nil {
+		return out, false, err
+	} else if bytes.Equal(data, []byte(cacheNegative)) {
+		return out, true, invar.ErrNotFound
+	}
+
+	err = c.codec.Unmarshal(data, &out)
+	return out, false, err
+}
+
+// setRaw set the serialized value and expiration of key, then bind it with tags.
+func (c *WingCache[T]) setRaw(key string, data []byte, expire int64, tags ...string) error {
+	con := c.conn.redisPool.Get()
+	defer con.Close()
+
+	nskey := c.nsKey(key)
+	if _, err := con.Do("SETEX", nskey, expire, data); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		tagkey := c.conn.NsKey(cacheTagPrefix + tag)
+		if _, err := cacheTag.Do(con, tagkey, nskey, expire); err != nil {
+			logger.W("Bind cache [key:"+key+"] tag:", tag, "err:", err)
+		}
+	}
+	return nil
+}
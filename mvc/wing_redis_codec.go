@@ -0,0 +1,101 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// CacheCodec marshal and unmarshal values to store into redis.
+type CacheCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// jsonCodec the codec to serialize value as JSON string.
+type jsonCodec struct{}
+
+// gzipJSONCodec the codec to serialize value as gzip compressed JSON.
+type gzipJSONCodec struct{}
+
+// msgpackCodec the codec to serialize value as MessagePack bytes.
+type msgpackCodec struct{}
+
+var (
+	// JSONCodec the default codec to serialize values as JSON.
+	JSONCodec CacheCodec = &jsonCodec{}
+
+	// GzipJSONCodec the codec to serialize large values as gzip compressed JSON.
+	GzipJSONCodec CacheCodec = &gzipJSONCodec{}
+
+	// MsgpackCodec the codec to serialize values as MessagePack.
+	MsgpackCodec CacheCodec = &msgpackCodec{}
+)
+
+// Marshal marshal the given value to JSON bytes.
+func (c *jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal unmarshal the JSON bytes to given value.
+func (c *jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// Marshal marshal the given value to JSON and compress by gzip.
+func (c *gzipJSONCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		zw.Close()
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decompress the gzip bytes and unmarshal JSON to given value.
+func (c *gzipJSONCodec) Unmarshal(data []byte, v any) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// Marshal marshal the given value to MessagePack bytes.
+func (c *msgpackCodec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal unmarshal the MessagePack bytes to given value.
+func (c *msgpackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
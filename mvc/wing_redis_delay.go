@@ -0,0 +1,379 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// DelayTask a delay task stored in redis delay queue.
+type DelayTask struct {
+	ID      string `json:"id"`      // unique task id, such as trade number
+	Topic   string `json:"topic"`   // task topic to distinguish business
+	Payload string `json:"payload"` // task custom datas
+	Retries int    `json:"retries"` // failed retry times
+	Created int64  `json:"created"` // task created unix time in seconds
+	claimed string // the stored task datas when claimed by worker
+}
+
+// DelayHandler handle the due delay task, return error to retry later.
+type DelayHandler func(task *DelayTask) error
+
+// DelayQueue a persistent delay queue base on redis sorted set scored by
+// execute time in milliseconds, it keep tasks when service restart, and
+// multiple service instances can consume the same queue, each due task will
+// be claimed by only one worker atomically.
+//
+// The claimed task will be invisible for lease duration, it will execute again
+// when the worker crashed before acknowledge, so handler must be idempotent.
+//
+// `USAGE` :
+//
+//	// close unpaid trade after 30 minutes
+//	dq := mvc.NewDelayQueue(mvc.WingRedis, "dq:trade")
+//	dq.Push(tradeno, "close", "", 30*time.Minute)
+//
+//	// cancel the delay task when trade paid
+//	dq.Cancel(tradeno)
+//
+//	// start worker to close unpaid trades
+//	dq.Start(func(task *mvc.DelayTask) error {
+//		query := "UPDATE trade SET status=? WHERE tno=? AND status=?"
+//		return mvc.WingHelper.Execute(query, invar.TSClosed, task.ID, invar.TSUnpaid)
+//	})
+type DelayQueue struct {
+	conn     *WingRedisConn // redis connection to store tasks
+	name     string         // queue name as keys prefix, the namespace will auto append
+	lease    int64          // claimed task invisible duration in milliseconds
+	backoff  int64          // retry backoff base duration in milliseconds
+	retries  int            // max retry times before move to dead tasks
+	batch    int            // max claim tasks count of each poll
+	interval time.Duration  // poll interval when no due tasks
+	stop     chan struct{}  // stop signal of worker
+	wg       sync.WaitGroup // wait worker exit
+}
+
+// Lua script to claim due tasks and extend their score by lease duration.
+//
+//	KEYS[1] : sorted set key of tasks schedule
+//	KEYS[2] : hash key of tasks datas
+//	ARGV[1] : max claim count
+//	ARGV[2] : lease duration in milliseconds
+const delayClaimScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, tonumber(ARGV[1]))
+local out = {}
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), id)
+	local data = redis.call('HGET', KEYS[2], id)
+	if data then
+		table.insert(out, data)
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return out`
+
+// Lua script to acknowledge, retry or bury the claimed task, it do nothing
+// when the task canceled or replaced by push during handling.
+//
+//	KEYS[1] : sorted set key of tasks schedule
+//	KEYS[2] : hash key of tasks datas
+//	KEYS[3] : hash key of dead tasks
+//	ARGV[1] : task id
+//	ARGV[2] : task datas when claimed
+//	ARGV[3] : action of 'ack', 'retry' or 'bury'
+//	ARGV[4] : updated task datas for retry or bury
+//	ARGV[5] : retry execute time in milliseconds
+const delayFinishScript = `
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == 'retry' then
+	redis.call('HSET', KEYS[2], ARGV[1], ARGV[4])
+	redis.call('ZADD', KEYS[1], tonumber(ARGV[5]), ARGV[1])
+else
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	redis.call('HDEL', KEYS[2], ARGV[1])
+	if ARGV[3] == 'bury' then
+		redis.call('HSET', KEYS[3], ARGV[1], ARGV[4])
+	end
+end
+return 1`
+
+// The max shift of retry backoff, the retry delay not grow after it.
+const delayMaxShift = 20
+
+var (
+	delayClaim  = redis.NewScript(2, delayClaimScript)
+	delayFinish = redis.NewScript(3, delayFinishScript)
+)
+
+// NewDelayQueue create a delay queue by unique name, it use default 60 seconds
+// lease, 5 seconds retry backoff, max 5 retries, and poll every second.
+func NewDelayQueue(conn *WingRedisConn, name string) *DelayQueue {
+	return &DelayQueue{
+		conn: conn, name: name, lease: 60000, backoff: 5000,
+		retries: 5, batch: 20, interval: time.Second,
+	}
+}
+
+// SetRetry set the max retry times and backoff base duration, the delay of
+// the n-th retry is backoff * 2^(n-1).
+func (q *DelayQueue) SetRetry(retries int, backoff time.Duration) {
+	if retries >= 0 {
+		q.retries = retries
+	}
+	if backoff > 0 {
+		q.backoff = backoff.Milliseconds()
+	}
+}
+
+// SetLease set the claimed task invisible duration, it should longer than
+// the max duration of handler execution.
+func (q *DelayQueue) SetLease(lease time.Duration) {
+	if lease > 0 {
+		q.lease = lease.Milliseconds()
+	}
+}
+
+// SetPoll set the poll interval and max claim count of each poll.
+func (q *DelayQueue) SetPoll(interval time.Duration, batch int) {
+	if interval > 0 {
+		q.interval = interval
+	}
+	if batch > 0 {
+		q.batch = batch
+	}
+}
+
+// Push add or replace a task to execute after the delay duration.
+func (q *DelayQueue) Push(id, topic, payload string, delay time.Duration) error {
+	return q.PushAt(id, topic, payload, time.Now().Add(delay).UnixMilli())
+}
+
+// PushAt add or replace a task to execute at the unix time in milliseconds.
+func (q *DelayQueue) PushAt(id, topic, payload string, execms int64) error {
+	task := &DelayTask{ID: id, Topic: topic, Payload: payload, Created: time.Now().Unix()}
+	return q.schedule(task, execms)
+}
+
+// Cancel remove the task by id, it return false when task unexist.
+func (q *DelayQueue) Cancel(id string) (bool, error) {
+	con := q.conn.redisPool.Get()
+	defer con.Close()
+
+	con.Send("MULTI")
+	con.Send("ZREM", q.zsetKey(), id)
+	con.Send("HDEL", q.hashKey(), id)
+	rsts, err := redis.Ints(con.Do("EXEC"))
+	if err != nil || len(rsts) != 2 {
+		return false, err
+	}
+	return rsts[0] > 0, nil
+}
+
+// Count return the waiting tasks count in queue.
+func (q *DelayQueue) Count() (int, error) {
+	con := q.conn.redisPool.Get()
+	defer con.Close()
+
+	return redis.Int(con.Do("ZCARD", q.zsetKey()))
+}
+
+// DeadTasks return the tasks which failed over max retry times.
+func (q *DelayQueue) DeadTasks() ([]*DelayTask, error) {
+	con := q.conn.redisPool.Get()
+	defer con.Close()
+
+	datas, err := redis.StringMap(con.Do("HGETALL", q.deadKey()))
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*DelayTask{}
+	for _, data := range datas {
+		task := &DelayTask{}
+		if err := json.Unmarshal([]byte(data), task); err == nil {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// Start start a worker goroutine to poll and handle due tasks, call Stop() to exit.
+func (q *DelayQueue) Start(handler DelayHandler) {
+	if q.stop != nil {
+		logger.W("Delay queue", q.name, "already started")
+		return
+	}
+
+	q.stop = make(chan struct{})
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		logger.I("Started delay queue:", q.name)
+
+		for {
+			select {
+			case <-q.stop:
+				logger.I("Stopped delay queue:", q.name)
+				return
+			default:
+			}
+
+			tasks, err := q.claim()
+			if err != nil {
+				logger.E("Claim delay tasks, err:", err)
+			}
+
+			for _, task := range tasks {
+				q.handle(task, handler)
+			}
+
+			if len(tasks) < q.batch {
+				select {
+				case <-q.stop:
+					logger.I("Stopped delay queue:", q.name)
+					return
+				case <-time.After(q.interval):
+				}
+			}
+		}
+	}()
+}
+
+// Stop stop the worker and wait the handling tasks finished.
+func (q *DelayQueue) Stop() {
+	if q.stop != nil {
+		close(q.stop)
+		q.wg.Wait()
+		q.stop = nil
+	}
+}
+
+// ----------------
+
+// claim claim due tasks atomically.
+func (q *DelayQueue) claim() ([]*DelayTask, error) {
+	con := q.conn.redisPool.Get()
+	defer con.Close()
+
+	datas, err := redis.Strings(delayClaim.Do(con, q.zsetKey(), q.hashKey(), q.batch, q.lease))
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*DelayTask{}
+	for _, data := range datas {
+		task := &DelayTask{claimed: data}
+		if err := json.Unmarshal([]byte(data), task); err != nil {
+			logger.E("Unmarshal delay task, err:", err)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// handle execute task handler, then acknowledge or retry the task.
+func (q *DelayQueue) handle(task *DelayTask, handler DelayHandler) {
+	err := invar.ErrCaseException
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.E("Handle delay task", task.ID, "panic:", r)
+			}
+		}()
+		err = handler(task)
+	}()
+
+	if err == nil {
+		q.finish(task, "ack", 0)
+		return
+	}
+
+	task.Retries++
+	if task.Retries > q.retries {
+		logger.E("Delay task", task.ID, "failed over", q.retries, "times, err:", err)
+		q.finish(task, "bury", 0)
+		return
+	}
+
+	logger.W("Delay task", task.ID, "failed, retry", task.Retries, "err:", err)
+	q.finish(task, "retry", time.Now().UnixMilli()+q.retryDelay(task.Retries))
+}
+
+// retryDelay return the retry delay in milliseconds, it is backoff * 2^(retries-1)
+// and the shift capped by delayMaxShift to avoid overflow.
+func (q *DelayQueue) retryDelay(retries int) int64 {
+	shift := min(max(retries-1, 0), delayMaxShift)
+	if q.backoff > math.MaxInt64>>shift {
+		return math.MaxInt64 >> 1
+	}
+	return q.backoff << shift
+}
+
+// schedule save task datas and set the execute time.
+func (q *DelayQueue) schedule(task *DelayTask, execms int64) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	con := q.conn.redisPool.Get()
+	defer con.Close()
+
+	con.Send("MULTI")
+	con.Send("HSET", q.hashKey(), task.ID, data)
+	con.Send("ZADD", q.zsetKey(), execms, task.ID)
+	_, err = con.Do("EXEC")
+	return err
+}
+
+// finish acknowledge, retry or bury the claimed task atomically, it skipped
+// when the task canceled or pushed again during handling.
+func (q *DelayQueue) finish(task *DelayTask, action string, execms int64) {
+	data, _ := json.Marshal(task)
+	con := q.conn.redisPool.Get()
+	defer con.Close()
+
+	done, err := redis.Int(delayFinish.Do(con, q.zsetKey(), q.hashKey(), q.deadKey(),
+		task.ID, task.claimed, action, data, execms))
+	if err != nil {
+		logger.E("Finish delay task", task.ID, action+", err:", err)
+	} else if done == 0 {
+		logger.W("Delay task", task.ID, "changed during handling, skip", action)
+	}
+}
+
+// zsetKey return the namespaced sorted set key of tasks schedule.
+func (q *DelayQueue) zsetKey() string {
+	return q.conn.NsKey(q.name + ":zset")
+}
+
+// hashKey return the namespaced hash key of tasks datas.
+func (q *DelayQueue) hashKey() string {
+	return q.conn.NsKey(q.name + ":tasks")
+}
+
+// deadKey return the namespaced hash key of dead tasks.
+func (q *DelayQueue) deadKey() string {
+	return q.conn.NsKey(q.name + ":dead")
+}
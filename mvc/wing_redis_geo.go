@@ -0,0 +1,281 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"database/sql"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// GeoLocation a member coordinates of geo index.
+type GeoLocation struct {
+	Member    string  `json:"member"`
+	Longitude float64 `json:"lng"`
+	Latitude  float64 `json:"lat"`
+}
+
+// GeoResult a member searched from geo index, the distance unit same as search unit.
+type GeoResult struct {
+	Member    string  `json:"member"`
+	Distance  float64 `json:"distance"`
+	Longitude float64 `json:"lng"`
+	Latitude  float64 `json:"lat"`
+}
+
+// GeoQuery the GEOSEARCH conditions, search from member when FromMember not
+// empty, or from the given longitude and latitude, and search by radius when
+// Radius over 0, or by box of width and height.
+type GeoQuery struct {
+	FromMember string  // center member, optional
+	Longitude  float64 // center longitude when FromMember empty
+	Latitude   float64 // center latitude when FromMember empty
+	Radius     float64 // search radius, use box search when 0
+	Width      float64 // box width of box search
+	Height     float64 // box height of box search
+	Unit       string  // distance unit, GeoUnitM as default
+	Sort       string  // results sort by distance, GeoASC or GeoDESC, optional
+	Count      int     // max results count, 0 is unlimited
+}
+
+// Distance units and sort options of GEO commands
+const (
+	GeoUnitM  = "m"    // meters
+	GeoUnitKM = "km"   // kilometers
+	GeoUnitMI = "mi"   // miles
+	GeoUnitFT = "ft"   // feet
+	GeoASC    = "ASC"  // sort nearest to farthest
+	GeoDESC   = "DESC" // sort farthest to nearest
+)
+
+// Max locations count of each GEOADD when sync geo index
+const geoSyncBatch = 500
+
+// GeoAdd add or update members coordinates into geo index of key, and return
+// the new added members count.
+//
+// see https://redis.io/commands/geoadd
+func (c *WingRedisConn) GeoAdd(key string, locs ...*GeoLocation) (int, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	return geoAdd(con, c.NsKey(key), locs)
+}
+
+// GeoRemove remove members from geo index of key.
+func (c *WingRedisConn) GeoRemove(key string, members ...string) (int, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	return redis.Int(con.Do("ZREM", redis.Args{}.Add(c.NsKey(key)).AddFlat(members)...))
+}
+
+// GeoDist return the distance between two members in given unit, it return
+// invar.ErrNotFound error when one or both of members unexist.
+//
+// see https://redis.io/commands/geodist
+func (c *WingRedisConn) GeoDist(key, member1, member2 string, unit ...string) (float64, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	dist, err := redis.Float64(con.Do("GEODIST", c.NsKey(key), member1, member2, geoUnit(unit...)))
+	if err == redis.ErrNil {
+		return 0, invar.ErrNotFound
+	}
+	return dist, err
+}
+
+// GeoPos return the coordinates of members, the unexist member will be nil.
+//
+// see https://redis.io/commands/geopos
+func (c *WingRedisConn) GeoPos(key string, members ...string) ([]*GeoLocation, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	replies, err := redis.Values(con.Do("GEOPOS", redis.Args{}.Add(c.NsKey(key)).AddFlat(members)...))
+	if err != nil {
+		return nil, err
+	}
+
+	locs := make([]*GeoLocation, len(members))
+	for i, reply := range replies {
+		if pos, err := redis.Float64s(reply, nil); err == nil && len(pos) == 2 {
+			locs[i] = &GeoLocation{Member: members[i], Longitude: pos[0], Latitude: pos[1]}
+		}
+	}
+	return locs, nil
+}
+
+// GeoRadius search members in radius of the given center, sort by nearest
+// to farthest and limit results count if count over 0.
+func (c *WingRedisConn) GeoRadius(key string, lng, lat, radius float64, unit string, count ...int) ([]*GeoResult, error) {
+	query := &GeoQuery{Longitude: lng, Latitude: lat, Radius: radius, Unit: unit, Sort: GeoASC}
+	if len(count) > 0 {
+		query.Count = count[0]
+	}
+	return c.GeoSearch(key, query)
+}
+
+// GeoBox search members in box of the given center, sort by nearest to
+// farthest and limit results count if count over 0.
+func (c *WingRedisConn) GeoBox(key string, lng, lat, width, height float64, unit string, count ...int) ([]*GeoResult, error) {
+	query := &GeoQuery{Longitude: lng, Latitude: lat, Width: width, Height: height, Unit: unit, Sort: GeoASC}
+	if len(count) > 0 {
+		query.Count = count[0]
+	}
+	return c.GeoSearch(key, query)
+}
+
+// GeoSearch search members by given query conditions, the results contain
+// distance and coordinates of each member, it require redis 6.2 or later.
+//
+// ---
+//
+//	// search nearest 20 stores in 5 km
+//	stores, err := mvc.WingRedis.GeoSearch("geo:stores", &mvc.GeoQuery{
+//		Longitude: 113.93, Latitude: 22.53, Radius: 5,
+//		Unit: mvc.GeoUnitKM, Sort: mvc.GeoASC, Count: 20,
+//	})
+//
+// see https://redis.io/commands/geosearch
+func (c *WingRedisConn) GeoSearch(key string, query *GeoQuery) ([]*GeoResult, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	unit, args := geoUnit(query.Unit), redis.Args{}.Add(c.NsKey(key))
+	if query.FromMember != "" {
+		args = args.Add("FROMMEMBER", query.FromMember)
+	} else {
+		args = args.Add("FROMLONLAT", query.Longitude, query.Latitude)
+	}
+
+	if query.Radius > 0 {
+		args = args.Add("BYRADIUS", query.Radius, unit)
+	} else if query.Width > 0 && query.Height > 0 {
+		args = args.Add("BYBOX", query.Width, query.Height, unit)
+	} else {
+		return nil, invar.ErrInvalidParams
+	}
+
+	switch query.Sort {
+	case GeoASC, GeoDESC:
+		args = args.Add(query.Sort)
+	}
+	if query.Count > 0 {
+		args = args.Add("COUNT", query.Count)
+	}
+
+	replies, err := redis.Values(con.Do("GEOSEARCH", args.Add("WITHDIST", "WITHCOORD")...))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*GeoResult{}
+	for _, reply := range replies {
+		var member string
+		var dist float64
+		var pos []float64
+
+		values, err := redis.Values(reply, nil)
+		if err != nil {
+			return nil, err
+		} else if _, err := redis.Scan(values, &member, &dist, &pos); err != nil {
+			return nil, err
+		}
+
+		result := &GeoResult{Member: member, Distance: dist}
+		if len(pos) == 2 {
+			result.Longitude, result.Latitude = pos[0], pos[1]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SyncGeoIndex rebuild geo index of key from database query, the query must
+// select member, longitude and latitude fields in order, the new index will
+// replace the old one atomically after all locations added.
+//
+// ---
+//
+//	query := "SELECT sid, lng, lat FROM store WHERE status=0"
+//	cnt, err := mvc.WingRedis.SyncGeoIndex("geo:stores", mvc.WingHelper, query)
+func (c *WingRedisConn) SyncGeoIndex(key string, provider *WingProvider, query string, args ...any) (int, error) {
+	locs := []*GeoLocation{}
+	if err := provider.QueryArray(query, func(rows *sql.Rows) error {
+		loc := &GeoLocation{}
+		if err := rows.Scan(&loc.Member, &loc.Longitude, &loc.Latitude); err != nil {
+			return err
+		}
+		locs = append(locs, loc)
+		return nil
+	}, args...); err != nil {
+		return 0, err
+	}
+
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	nskey := c.NsKey(key)
+	if len(locs) == 0 {
+		_, err := con.Do("DEL", nskey)
+		return 0, err
+	}
+
+	tmpkey := nskey + ":syncing"
+	if _, err := con.Do("DEL", tmpkey); err != nil {
+		return 0, err
+	}
+
+	for start := 0; start < len(locs); start += geoSyncBatch {
+		end := start + geoSyncBatch
+		if end > len(locs) {
+			end = len(locs)
+		}
+
+		if _, err := geoAdd(con, tmpkey, locs[start:end]); err != nil {
+			con.Do("DEL", tmpkey)
+			return 0, err
+		}
+	}
+
+	if _, err := con.Do("RENAME", tmpkey, nskey); err != nil {
+		return 0, err
+	}
+	logger.I("Synced", len(locs), "locations into geo index:", key)
+	return len(locs), nil
+}
+
+// geoAdd add locations into the namespaced geo key.
+func geoAdd(con redis.Conn, nskey string, locs []*GeoLocation) (int, error) {
+	if len(locs) == 0 {
+		return 0, nil
+	}
+
+	args := redis.Args{}.Add(nskey)
+	for _, loc := range locs {
+		args = args.Add(loc.Longitude, loc.Latitude, loc.Member)
+	}
+	return redis.Int(con.Do("GEOADD", args...))
+}
+
+// geoUnit return the given distance unit, or GeoUnitM as default.
+func geoUnit(unit ...string) string {
+	if len(unit) > 0 {
+		switch unit[0] {
+		case GeoUnitM, GeoUnitKM, GeoUnitMI, GeoUnitFT:
+			return unit[0]
+		}
+	}
+	return GeoUnitM
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/utils"
+)
+
+// PfAdd add elements into the HyperLogLog of key, it return true if the
+// approximated cardinality changed.
+//
+// see https://redis.io/commands/pfadd
+func (c *WingRedisConn) PfAdd(key string, elements ...any) (bool, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	return redis.Bool(con.Do("PFADD", redis.Args{}.Add(c.NsKey(key)).Add(elements...)...))
+}
+
+// PfCount return the approximated cardinality of the HyperLogLog keys union.
+//
+// see https://redis.io/commands/pfcount
+func (c *WingRedisConn) PfCount(keys ...string) (int64, error) {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	return redis.Int64(con.Do("PFCOUNT", redis.Args{}.AddFlat(c.NsArrKeys(keys))...))
+}
+
+// PfMerge merge the HyperLogLog of source keys into the dest key.
+//
+// see https://redis.io/commands/pfmerge
+func (c *WingRedisConn) PfMerge(dest string, sources ...string) error {
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	args := redis.Args{}.Add(c.NsKey(dest)).AddFlat(c.NsArrKeys(sources))
+	_, err := con.Do("PFMERGE", args...)
+	return err
+}
+
+// ----------------
+
+// HllDayKey return the day bucket key like 'key:20060102' of given unix
+// time, or today when not set.
+func HllDayKey(key string, unix ...int64) string {
+	day := utils.TodayUnix()
+	if len(unix) > 0 && unix[0] > 0 {
+		day = utils.DayUnix(unix[0])
+	}
+	return key + ":" + utils.FormatUnix(utils.DateNoneHyphen, day)
+}
+
+// HllWeekKey return the week bucket key like 'key:w20060102' of given unix
+// time, or current week when not set, the date is monday of the week.
+func HllWeekKey(key string, unix ...int64) string {
+	day := utils.TodayUnix()
+	if len(unix) > 0 && unix[0] > 0 {
+		day = utils.DayUnix(unix[0])
+	}
+	return key + ":w" + utils.FormatUnix(utils.DateNoneHyphen, weekStartUnix(day))
+}
+
+// HllMonthKey return the month bucket key like 'key:m200601' of given unix
+// time, or current month when not set.
+func HllMonthKey(key string, unix ...int64) string {
+	day := utils.TodayUnix()
+	if len(unix) > 0 && unix[0] > 0 {
+		day = utils.DayUnix(unix[0])
+	}
+	return key + ":m" + time.Unix(day, 0).Format("200601")
+}
+
+// UVAdd add visitors into today bucket of key, and set the bucket expiration
+// in seconds if expire over 0, it return true if the today UV changed.
+//
+// ---
+//
+//	// count store visitors, and hold day buckets 40 days
+//	mvc.WingRedis.UVAdd("uv:store:"+sid, 40*86400, uuid)
+//	todayuv, _ := mvc.WingRedis.UVCountDays("uv:store:"+sid, 1)
+//	monthuv, _ := mvc.WingRedis.UVCountMonth("uv:store:" + sid)
+func (c *WingRedisConn) UVAdd(key string, expire int64, visitors ...any) (bool, error) {
+	daykey := HllDayKey(key)
+	changed, err := c.PfAdd(daykey, visitors...)
+	if err == nil && expire > 0 {
+		c.Expire(daykey, expire)
+	}
+	return changed, err
+}
+
+// UVCountDays return the unique visitors of key in last days, include today.
+func (c *WingRedisConn) UVCountDays(key string, days int) (int64, error) {
+	if days < 1 {
+		days = 1
+	}
+	return c.PfCount(hllDayKeys(key, utils.DaysUnix(1-days), days)...)
+}
+
+// UVCountWeek return the unique visitors of key in current week from monday.
+func (c *WingRedisConn) UVCountWeek(key string) (int64, error) {
+	start := weekStartUnix(utils.TodayUnix())
+	days := int((utils.TodayUnix()-start)/86400) + 1
+	return c.PfCount(hllDayKeys(key, start, days)...)
+}
+
+// UVCountMonth return the unique visitors of key in current month from the first day.
+func (c *WingRedisConn) UVCountMonth(key string) (int64, error) {
+	days := time.Now().Day()
+	return c.PfCount(hllDayKeys(key, utils.DaysUnix(1-days), days)...)
+}
+
+// UVMergeWeek merge the day buckets of the week into week bucket key, the
+// unix is any time in target week.
+func (c *WingRedisConn) UVMergeWeek(key string, unix int64) error {
+	start := weekStartUnix(utils.DayUnix(unix))
+	return c.PfMerge(HllWeekKey(key, unix), hllDayKeys(key, start, 7)...)
+}
+
+// UVMergeMonth merge the day buckets of the month into month bucket key, the
+// unix is any time in target month.
+func (c *WingRedisConn) UVMergeMonth(key string, unix int64) error {
+	t := time.Unix(unix, 0)
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+	days := first.AddDate(0, 1, -1).Day()
+	return c.PfMerge(HllMonthKey(key, unix), hllDayKeys(key, first.Unix(), days)...)
+}
+
+// hllDayKeys return the day bucket keys of given days from start day.
+func hllDayKeys(key string, start int64, days int) []string {
+	keys, st := []string{}, time.Unix(start, 0)
+	for i := 0; i < days; i++ {
+		keys = append(keys, HllDayKey(key, st.AddDate(0, 0, i).Unix()))
+	}
+	return keys
+}
+
+// weekStartUnix return the monday unix time at 0:00:00 of the given day.
+func weekStartUnix(day int64) int64 {
+	t := time.Unix(day, 0)
+	offset := (int(t.Weekday()) + 6) % 7 // monday as the first day
+	return t.AddDate(0, 0, -offset).Unix()
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+)
+
+// The follow helpers serialize struct values by the codec of WingRedisConn,
+// which set by WingRedis.SetCodec(), and return invar.ErrNotFound error when
+// the key unexist.
+//
+// ---
+//
+//	err := mvc.SetJSON(mvc.WingRedis, "profile:"+uuid, profile, mvc.OptEX, int64(600))
+//	profile, err := mvc.GetJSON[types.Profile](mvc.WingRedis, "profile:"+uuid)
+//	profiles, err := mvc.MGetJSON[types.Profile](mvc.WingRedis, keys...)
+
+// SetJSON serialize and set the value of key, the options same as Set().
+func SetJSON(c *WingRedisConn, key string, value any, options ...any) error {
+	data, err := c.GetCodec().Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, data, options...)
+}
+
+// SetExJSON serialize and set the value and expiration in seconds of key.
+func SetExJSON(c *WingRedisConn, key string, value any, expire int64) error {
+	data, err := c.GetCodec().Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.SetEx(key, data, expire)
+}
+
+// GetJSON get and unserialize the value of key.
+func GetJSON[T any](c *WingRedisConn, key string) (T, error) {
+	return decodeJSON[T](c, key)
+}
+
+// GetDelJSON get, unserialize and delete the value of key.
+func GetDelJSON[T any](c *WingRedisConn, key string) (T, error) {
+	return decodeJSON[T](c, key, CusOptDel)
+}
+
+// GetExJSON get and unserialize the value of key, and optionally set its expiration.
+func GetExJSON[T any](c *WingRedisConn, key string, option string, expire int64) (T, error) {
+	return decodeJSON[T](c, key, option, expire)
+}
+
+// MGetJSON get and unserialize the values of keys, the unexist keys not contain
+// in returned map.
+//
+// see https://redis.io/commands/mget
+func MGetJSON[T any](c *WingRedisConn, keys ...string) (map[string]T, error) {
+	out := make(map[string]T)
+	if len(keys) == 0 {
+		return out, nil
+	}
+
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	values, err := redis.ByteSlices(con.Do("MGET", redis.Args{}.AddFlat(c.NsArrKeys(keys))...))
+	if err != nil {
+		return nil, err
+	}
+
+	codec := c.GetCodec()
+	for i, data := range values {
+		if data == nil {
+			continue // unexist key
+		}
+
+		var value T
+		if err := codec.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		out[keys[i]] = value
+	}
+	return out, nil
+}
+
+// MSetJSON serialize and set the values of keys in once.
+//
+// see https://redis.io/commands/mset
+func MSetJSON[T any](c *WingRedisConn, values map[string]T) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	codec, args := c.GetCodec(), redis.Args{}
+	for key, value := range values {
+		data, err := codec.Marshal(value)
+		if err != nil {
+			return err
+		}
+		args = args.Add(c.NsKey(key), data)
+	}
+
+	con := c.redisPool.Get()
+	defer con.Close()
+
+	_, err := con.Do("MSET", args...)
+	return err
+}
+
+// decodeJSON get value of key by given options, then unserialize it
+// by codec of WingRedisConn.
+func decodeJSON[T any](c *WingRedisConn, key string, options ...any) (T, error) {
+	var out T
+	data, err := redis.Bytes(c.getWithOptions(key, options...))
+	if err != nil {
+		if err == redis.ErrNil {
+			return out, invar.ErrNotFound
+		}
+		return out, err
+	}
+
+	err = c.GetCodec().Unmarshal(data, &out)
+	return out, err
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/utils"
+)
+
+// RateLimiter limit request rate of the given key, it return true if allowed,
+// or false and the retry after duration in milliseconds when denied.
+type RateLimiter interface {
+	Allow(key string) (bool, int64, error)
+}
+
+// LimitKeyFunc return the limit key of request, return empty to skip limit.
+type LimitKeyFunc func(ctx *context.Context) string
+
+// SlidingLimiter limit requests count in a sliding time window, it record
+// requests into redis sorted set and check them atomically by lua script.
+type SlidingLimiter struct {
+	conn   *WingRedisConn // redis connection to record requests
+	prefix string         // limit keys prefix, the namespace will auto append
+	limit  int64          // max requests count in window
+	window int64          // sliding window duration in milliseconds
+}
+
+// BucketLimiter limit requests by token bucket, the tokens refill on a fixed
+// rate and the bucket capacity allow bursts, it store bucket state into redis
+// hash and consume token atomically by lua script.
+type BucketLimiter struct {
+	conn   *WingRedisConn // redis connection to store buckets
+	prefix string         // limit keys prefix, the namespace will auto append
+	rate   float64        // tokens refill count per second
+	burst  int64          // max tokens of bucket
+}
+
+// Lua script to check and record request in sliding window, it use redis
+// server time to keep the same clock across multiple service instances, and
+// replicate commands by effects for redis 3.2 ~ 4.x not allow writes after
+// TIME command in script.
+//
+//	KEYS[1] : sorted set key of requests
+//	ARGV[1] : max requests count
+//	ARGV[2] : window duration in milliseconds
+//	ARGV[3] : unique member of current request
+const slidingLimitScript = `
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit, window = tonumber(ARGV[1]), tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, 0}
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	return {0, tonumber(oldest[2]) + window - now}
+end
+return {0, window}`
+
+// Lua script to refill and consume a token from bucket, it use redis server
+// time and replicate commands by effects as sliding window script.
+//
+//	KEYS[1] : hash key of bucket
+//	ARGV[1] : tokens refill count per second
+//	ARGV[2] : max tokens of bucket
+const bucketLimitScript = `
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate, burst = tonumber(ARGV[1]), tonumber(ARGV[2])
+local vals = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(vals[1]) or burst
+local ts = tonumber(vals[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens, allowed = tokens - 1, 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, retry}`
+
+var (
+	slidingScript = redis.NewScript(1, slidingLimitScript)
+	bucketScript  = redis.NewScript(1, bucketLimitScript)
+)
+
+// NewSlidingLimiter create a sliding window limiter to allow max limit
+// requests in the window duration.
+func NewSlidingLimiter(conn *WingRedisConn, prefix string, limit int64, window time.Duration) *SlidingLimiter {
+	return &SlidingLimiter{
+		conn: conn, prefix: prefix, limit: limit, window: window.Milliseconds(),
+	}
+}
+
+// NewBucketLimiter create a token bucket limiter to refill rate tokens per
+// second and allow max burst requests at once, the non-positive rate and
+// burst will clamp to 1.
+func NewBucketLimiter(conn *WingRedisConn, prefix string, rate float64, burst int64) *BucketLimiter {
+	if rate <= 0 || burst <= 0 {
+		logger.W("Invalid bucket limiter rate:", rate, "burst:", burst, ", clamp to 1")
+		rate, burst = max(rate, 1), max(burst, 1)
+	}
+	return &BucketLimiter{conn: conn, prefix: prefix, rate: rate, burst: burst}
+}
+
+// Allow check and record the request of given key in sliding window.
+func (l *SlidingLimiter) Allow(key string) (bool, int64, error) {
+	con := l.conn.redisPool.Get()
+	defer con.Close()
+
+	member := fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
+	rst, err := redis.Int64s(slidingScript.Do(con, l.conn.NsKey(l.prefix+key), l.limit, l.window, member))
+	if err != nil || len(rst) != 2 {
+		return true, 0, err
+	}
+	return rst[0] == 1, rst[1], nil
+}
+
+// Allow consume a token from bucket of given key.
+func (l *BucketLimiter) Allow(key string) (bool, int64, error) {
+	con := l.conn.redisPool.Get()
+	defer con.Close()
+
+	rst, err := redis.Int64s(bucketScript.Do(con, l.conn.NsKey(l.prefix+key), l.rate, l.burst))
+	if err != nil || len(rst) != 2 {
+		return true, 0, err
+	}
+	return rst[0] == 1, rst[1], nil
+}
+
+// ----------------
+
+// LimitByIP return client ip as limit key.
+func LimitByIP(ctx *context.Context) string {
+	return utils.GetIP(ctx.Request.RemoteAddr)
+}
+
+// LimitByUUID return the authenticated account uuid as limit key, it auth
+// request by GAuthSchemes and skip limit when unauthed, notice that the
+// WAuthController will auth request again by its schemes.
+func LimitByUUID(ctx *context.Context) string {
+	for _, scheme := range GAuthSchemes {
+		if scheme.Match(ctx) {
+			if claims, _, err := scheme.Auth(ctx); err == nil && claims != nil {
+				return claims.Uuid
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// LimitByRoute return request url path as limit key.
+func LimitByRoute(ctx *context.Context) string {
+	return ctx.Input.URL()
+}
+
+// LimitByRouteIP return request url path and client ip as limit key.
+func LimitByRouteIP(ctx *context.Context) string {
+	return ctx.Input.URL() + ":" + utils.GetIP(ctx.Request.RemoteAddr)
+}
+
+// RateLimitFilter return a beego filter to limit request rate by given limiter,
+// it response 429 with Retry-After header when denied.
+//
+// `USAGE` :
+//
+//	// allow 5 sms send requests per minute of one client ip
+//	limiter := mvc.NewSlidingLimiter(mvc.WingRedis, "limit:sms:", 5, time.Minute)
+//	beego.InsertFilter("/v3/sms/*", beego.BeforeRouter, mvc.RateLimitFilter(limiter, mvc.LimitByIP))
+//
+//	// allow 60 requests per minute of one authenticated account
+//	accLimiter := mvc.NewBucketLimiter(mvc.WingRedis, "limit:acc:", 1, 60)
+//	beego.InsertFilter("/v3/acc/*", beego.BeforeRouter, mvc.RateLimitFilter(accLimiter, mvc.LimitByUUID))
+func RateLimitFilter(limiter RateLimiter, keyfunc LimitKeyFunc) beego.FilterFunc {
+	return func(ctx *context.Context) {
+		key := keyfunc(ctx)
+		if key == "" {
+			return
+		}
+
+		if allowed, retry := checkRateLimit(limiter, key); !allowed {
+			logger.E("Respone ERR:", invar.E429TooManyRequests, "> Rate limited", key)
+			setRetryAfter(ctx, retry)
+			ctx.ResponseWriter.WriteHeader(invar.E429TooManyRequests)
+			ctx.ResponseWriter.Write([]byte(""))
+		}
+	}
+}
+
+// AllowRate check the request rate of given key such as account uuid, it response
+// 429 with Retry-After header and return false when denied.
+//
+// ---
+//
+//	if uuid := c.AuthRequestHeader(); uuid != "" && c.AllowRate(limiter, uuid) {
+//		c.ResponJSON(service.SendSms(uuid))
+//	}
+func (c *WingController) AllowRate(limiter RateLimiter, key string) bool {
+	allowed, retry := checkRateLimit(limiter, key)
+	if !allowed {
+		setRetryAfter(c.Ctx, retry)
+		c.E429TooMany("Rate limited " + key)
+	}
+	return allowed
+}
+
+// checkRateLimit check request rate by limiter, it allow the request when
+// case redis error to keep service available.
+func checkRateLimit(limiter RateLimiter, key string) (bool, int64) {
+	allowed, retry, err := limiter.Allow(key)
+	if err != nil {
+		logger.W("Check rate limit [key:"+key+"] err:", err)
+		return true, 0
+	}
+	return allowed, retry
+}
+
+// setRetryAfter set Retry-After header in seconds from milliseconds.
+func setRetryAfter(ctx *context.Context, retry int64) {
+	seconds := (retry + 999) / 1000
+	if seconds <= 0 {
+		seconds = 1
+	}
+	ctx.Output.Header("Retry-After", strconv.FormatInt(seconds, 10))
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// ScanIterator a cursor-based iterator to scan keys of current namespace,
+// it never use KEYS commond to avoid blocking redis server.
+//
+// `USAGE` :
+//
+//	it := mvc.WingRedis.ScanIter("order:*", 100)
+//	for keys, ok := it.Next(); ok; keys, ok = it.Next() {
+//		// the keys are origin keys without namespace
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		logger.E("Scan order keys, err:", err)
+//	}
+type ScanIterator struct {
+	conn    *WingRedisConn // redis connection to scan keys
+	match   string         // namespaced MATCH pattern
+	count   int64          // COUNT hint of each scan
+	keytype string         // TYPE filter, since redis 6.0
+	cursor  int64          // current scan cursor
+	started bool           // whether first scan executed
+	err     error          // the last scan error
+}
+
+// ScanKeysCallback handle one batch of scaned origin keys, return error to abort.
+type ScanKeysCallback func(keys []string) error
+
+// ScanIter create a SCAN iterator by given match pattern of origin keys, the
+// count is the hint keys count of each scan, and the optional keytype maybe
+// 'string', 'list', 'set', 'zset', 'hash' or 'stream' to filter keys type.
+//
+// see command [scan](https://redis.io/commands/scan)
+func (c *WingRedisConn) ScanIter(match string, count int64, keytype ...string) *ScanIterator {
+	if match == "" {
+		match = "*"
+	}
+
+	// escape glob chars of namespace, only match pattern of origin keys
+	nsmatch := globEscape(c.serviceNamespace) + match
+	it := &ScanIterator{conn: c, match: nsmatch, count: count}
+	if len(keytype) > 0 {
+		it.keytype = keytype[0]
+	}
+	return it
+}
+
+// Next scan and return the next batch of origin keys, it return false when
+// scan finished or case error, the returned batch maybe empty.
+func (it *ScanIterator) Next() ([]string, bool) {
+	if it.err != nil || (it.started && it.cursor == 0) {
+		return nil, false
+	}
+
+	con := it.conn.redisPool.Get()
+	defer con.Close()
+
+	args := redis.Args{}.Add(it.cursor, "MATCH", it.match)
+	if it.count > 0 {
+		args = args.Add("COUNT", it.count)
+	}
+	if it.keytype != "" {
+		args = args.Add("TYPE", it.keytype)
+	}
+
+	values, err := redis.Values(con.Do("SCAN", args...))
+	if err != nil {
+		it.err = err
+		return nil, false
+	}
+
+	var nskeys []string
+	if _, err := redis.Scan(values, &it.cursor, &nskeys); err != nil {
+		it.err = err
+		return nil, false
+	}
+	it.started = true
+
+	keys := []string{}
+	for _, nskey := range nskeys {
+		keys = append(keys, strings.TrimPrefix(nskey, it.conn.serviceNamespace))
+	}
+	return keys, true
+}
+
+// Err return the error case scan aborted.
+func (it *ScanIterator) Err() error {
+	return it.err
+}
+
+// ScanKeys scan all origin keys by given match pattern and optional keytype.
+func (c *WingRedisConn) ScanKeys(match string, keytype ...string) ([]string, error) {
+	out := []string{}
+	err := c.ScanFetch(match, func(keys []string) error {
+		out = append(out, keys...)
+		return nil
+	}, keytype...)
+	return out, err
+}
+
+// ScanFetch scan keys by given match pattern and handle them in batches.
+func (c *WingRedisConn) ScanFetch(match string, cb ScanKeysCallback, keytype ...string) error {
+	it := c.ScanIter(match, 100, keytype...)
+	for keys, ok := it.Next(); ok; keys, ok = it.Next() {
+		if len(keys) > 0 {
+			if err := cb(keys); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Err()
+}
+
+// DeletePattern delete all keys matched the pattern, and return deleted keys count.
+func (c *WingRedisConn) DeletePattern(match string) (int, error) {
+	return c.removePattern("DEL", match)
+}
+
+// UnlinkPattern unlink all keys matched the pattern, the memory reclaim in
+// background by redis server, it return unlinked keys count.
+//
+// see command [unlink](https://redis.io/commands/unlink)
+func (c *WingRedisConn) UnlinkPattern(match string) (int, error) {
+	return c.removePattern("UNLINK", match)
+}
+
+// ExpirePattern set time to live in seconds for all keys matched the pattern,
+// and return the count of keys which set expiration.
+func (c *WingRedisConn) ExpirePattern(match string, expire int64) (int, error) {
+	total := 0
+	err := c.ScanFetch(match, func(keys []string) error {
+		con := c.redisPool.Get() // not hold connection when scanning
+		defer con.Close()
+
+		for _, key := range keys {
+			if set, err := redis.Bool(con.Do("EXPIRE", c.NsKey(key), expire)); err != nil {
+				return err
+			} else if set {
+				total++
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// DumpPattern serialize all keys matched the pattern by DUMP commond, the
+// returned datas can restore by RESTORE commond.
+//
+// see command [dump](https://redis.io/commands/dump)
+func (c *WingRedisConn) DumpPattern(match string) (map[string][]byte, error) {
+	dumps := make(map[string][]byte)
+	err := c.ScanFetch(match, func(keys []string) error {
+		con := c.redisPool.Get() // not hold connection when scanning
+		defer con.Close()
+
+		for _, key := range keys {
+			data, err := redis.Bytes(con.Do("DUMP", c.NsKey(key)))
+			if err == redis.ErrNil {
+				continue // the key maybe deleted after scaned
+			} else if err != nil {
+				return err
+			}
+			dumps[key] = data
+		}
+		return nil
+	})
+	return dumps, err
+}
+
+// PurgeNamespace unlink all keys of current service namespace, it will do
+// nothing when namespace is empty to protect other services datas.
+func (c *WingRedisConn) PurgeNamespace() (int, error) {
+	if c.serviceNamespace == "" {
+		logger.W("Redis: empty namespace, abort purge")
+		return 0, nil
+	}
+	return c.UnlinkPattern("*")
+}
+
+// removePattern delete or unlink keys matched the pattern in batches.
+func (c *WingRedisConn) removePattern(commond, match string) (int, error) {
+	total := 0
+	err := c.ScanFetch(match, func(keys []string) error {
+		con := c.redisPool.Get() // not hold connection when scanning
+		defer con.Close()
+
+		cnt, err := redis.Int(con.Do(commond, redis.Args{}.AddFlat(c.NsArrKeys(keys))...))
+		total += cnt
+		return err
+	})
+	return total, err
+}
+
+// globEscape escape the glob special chars of MATCH pattern.
+func globEscape(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(`*?[]\`, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
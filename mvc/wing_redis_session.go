@@ -0,0 +1,361 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego/session"
+	"github.com/gomodule/redigo/redis"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// RedisSessionProvider the beego session provider name base on mvc.WingRedis,
+// set it in /conf/app.config file as:
+//
+// ---
+//
+//	sessionon = true
+//	sessionprovider = "wingredis"
+//	; optional session keys prefix, default 'session:'
+//	sessionproviderconfig = "session:"
+//
+// ---
+//
+// `NOTICE` : you must call mvc.OpenRedis() before beego.Run().
+const RedisSessionProvider = "wingredis"
+
+// redisSessionStore beego session store saved in redis.
+type redisSessionStore struct {
+	provider *redisSessionProvider
+	sid      string
+	lock     sync.RWMutex
+	values   map[any]any
+}
+
+// redisSessionProvider beego session provider base on mvc.WingRedis.
+type redisSessionProvider struct {
+	maxlifetime int64
+	prefix      string
+}
+
+// TokenSession the login token session informations.
+type TokenSession struct {
+	Token   string `json:"token"`
+	Uuid    string `json:"uuid"`
+	Role    string `json:"role"`
+	Created int64  `json:"created"`
+}
+
+// TokenStore a server side login token store with sliding expiration, it map
+// token to account uuid and role, and index tokens by uuid to list or revoke
+// all sessions of one account.
+//
+// `USAGE` :
+//
+//	// setup token store to auth WAuthController requests locally
+//	mvc.GTokenStore = mvc.NewTokenStore(mvc.WingRedis, "token:", 7*86400)
+//
+//	// create token session after account login success
+//	mvc.GTokenStore.Create(token, uuid, invar.WRoleUser)
+//
+//	// revoke all sessions after reset password
+//	mvc.GTokenStore.RevokeAll(uuid)
+type TokenStore struct {
+	conn   *WingRedisConn // redis connection to store tokens
+	prefix string         // token keys prefix, the namespace will auto append
+	expire int64          // sliding expiration in seconds
+}
+
+// Global token store to auth token from http header locally,
+// it will use GAuthHandlerFunc to auth token when not set.
+var GTokenStore *TokenStore
+
+// Default keys prefix of beego sessions
+const sessionDefPrefix = "session:"
+
+func init() {
+	session.Register(RedisSessionProvider, &redisSessionProvider{prefix: sessionDefPrefix})
+}
+
+// NewTokenStore create a token store, the expire is sliding expiration in seconds,
+// each verified token will extend expiration.
+func NewTokenStore(conn *WingRedisConn, prefix string, expire int64) *TokenStore {
+	return &TokenStore{conn: conn, prefix: prefix, expire: expire}
+}
+
+// Create save the token session of account uuid and role.
+func (s *TokenStore) Create(token, uuid, role string) error {
+	data, err := json.Marshal(&TokenSession{
+		Token: token, Uuid: uuid, Role: role, Created: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	uuidkey := s.uuidKey(uuid)
+	con.Send("MULTI")
+	con.Send("SETEX", s.tokenKey(token), s.expire, data)
+	con.Send("SADD", uuidkey, token)
+	con.Send("EXPIRE", uuidkey, s.expire)
+	_, err = con.Do("EXEC")
+	return err
+}
+
+// Verify return the token session and extend expiration, it return
+// invar.ErrInvalidToken error when token unexist or expired.
+func (s *TokenStore) Verify(token string) (*TokenSession, error) {
+	ts, err := s.get(token)
+	if err != nil {
+		return nil, err
+	}
+
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	con.Send("EXPIRE", s.tokenKey(token), s.expire)
+	con.Send("EXPIRE", s.uuidKey(ts.Uuid), s.expire)
+	if _, err := con.Do(""); err != nil {
+		logger.W("Extend token expiration, err:", err)
+	}
+	return ts, nil
+}
+
+// Sessions return all alive token sessions of account uuid.
+func (s *TokenStore) Sessions(uuid string) ([]*TokenSession, error) {
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	tokens, err := redis.Strings(con.Do("SMEMBERS", s.uuidKey(uuid)))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*TokenSession{}
+	for _, token := range tokens {
+		if ts, err := s.get(token); err == nil {
+			sessions = append(sessions, ts)
+		} else if err == invar.ErrInvalidToken {
+			con.Do("SREM", s.uuidKey(uuid), token) // remove expired token
+		}
+	}
+	return sessions, nil
+}
+
+// Revoke delete the token session.
+func (s *TokenStore) Revoke(token string) error {
+	ts, err := s.get(token)
+	if err != nil {
+		if err == invar.ErrInvalidToken {
+			return nil
+		}
+		return err
+	}
+
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	con.Send("MULTI")
+	con.Send("DEL", s.tokenKey(token))
+	con.Send("SREM", s.uuidKey(ts.Uuid), token)
+	_, err = con.Do("EXEC")
+	return err
+}
+
+// RevokeAll delete all token sessions of account uuid, and return revoked count.
+func (s *TokenStore) RevokeAll(uuid string) (int, error) {
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	uuidkey := s.uuidKey(uuid)
+	tokens, err := redis.Strings(con.Do("SMEMBERS", uuidkey))
+	if err != nil {
+		return 0, err
+	}
+
+	args := redis.Args{}.Add(uuidkey)
+	for _, token := range tokens {
+		args = args.Add(s.tokenKey(token))
+	}
+
+	if _, err := con.Do("DEL", args...); err != nil {
+		return 0, err
+	}
+	return len(tokens), nil
+}
+
+// AuthHandler return an AuthHandlerFunc to verify token by current store,
+// the returned password always empty.
+func (s *TokenStore) AuthHandler() AuthHandlerFunc {
+	return func(token string) (string, string) {
+		ts, err := s.Verify(token)
+		if err != nil {
+			return "", ""
+		}
+		return ts.Uuid, ""
+	}
+}
+
+// get get the token session without extend expiration.
+func (s *TokenStore) get(token string) (*TokenSession, error) {
+	con := s.conn.redisPool.Get()
+	defer con.Close()
+
+	data, err := redis.Bytes(con.Do("GET", s.tokenKey(token)))
+	if err != nil {
+		if err == redis.ErrNil {
+			return nil, invar.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	ts := &TokenSession{}
+	if err := json.Unmarshal(data, ts); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// tokenKey return the namespaced key of token session.
+func (s *TokenStore) tokenKey(token string) string {
+	return s.conn.NsKey(s.prefix + "t:" + token)
+}
+
+// uuidKey return the namespaced key of account tokens set.
+func (s *TokenStore) uuidKey(uuid string) string {
+	return s.conn.NsKey(s.prefix + "u:" + uuid)
+}
+
+// ----------------
+
+// Set set value to redis session.
+func (rs *redisSessionStore) Set(key, value any) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.values[key] = value
+	return nil
+}
+
+// Get get value from redis session.
+func (rs *redisSessionStore) Get(key any) any {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	return rs.values[key]
+}
+
+// Delete delete value in redis session.
+func (rs *redisSessionStore) Delete(key any) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	delete(rs.values, key)
+	return nil
+}
+
+// Flush clear all values in redis session.
+func (rs *redisSessionStore) Flush() error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.values = make(map[any]any)
+	return nil
+}
+
+// SessionID get redis session id.
+func (rs *redisSessionStore) SessionID() string {
+	return rs.sid
+}
+
+// SessionRelease save session values to redis.
+func (rs *redisSessionStore) SessionRelease(w http.ResponseWriter) {
+	rs.lock.RLock()
+	data, err := session.EncodeGob(rs.values)
+	rs.lock.RUnlock()
+	if err != nil {
+		logger.E("Encode session values, err:", err)
+		return
+	}
+
+	if err := WingRedis.SetEx(rs.provider.key(rs.sid), data, rs.provider.maxlifetime); err != nil {
+		logger.E("Save session", rs.sid, "err:", err)
+	}
+}
+
+// SessionInit init redis session provider, the config is optional keys prefix.
+func (rp *redisSessionProvider) SessionInit(maxlifetime int64, config string) error {
+	if WingRedis == nil {
+		return invar.ErrInvalidConfigs
+	}
+
+	rp.maxlifetime = maxlifetime
+	if prefix := strings.TrimSpace(config); prefix != "" {
+		rp.prefix = prefix
+	}
+	return nil
+}
+
+// SessionRead read redis session by sid, or create a new one when unexist.
+func (rp *redisSessionProvider) SessionRead(sid string) (session.Store, error) {
+	values := make(map[any]any)
+	data, err := WingRedis.GetExBytes(rp.key(sid), OptEX, rp.maxlifetime)
+	if err != nil && err != redis.ErrNil {
+		return nil, err
+	} else if err == nil && len(data) > 0 {
+		if values, err = session.DecodeGob(data); err != nil {
+			return nil, err
+		}
+	}
+	return &redisSessionStore{provider: rp, sid: sid, values: values}, nil
+}
+
+// SessionExist check redis session exist by sid.
+func (rp *redisSessionProvider) SessionExist(sid string) bool {
+	exist, _ := WingRedis.Exist(rp.key(sid))
+	return exist
+}
+
+// SessionRegenerate generate new sid for redis session.
+func (rp *redisSessionProvider) SessionRegenerate(oldsid, sid string) (session.Store, error) {
+	if exist, _ := WingRedis.Exist(rp.key(oldsid)); exist {
+		con := WingRedis.redisPool.Get()
+		defer con.Close()
+
+		if _, err := con.Do("RENAME", WingRedis.NsKey(rp.key(oldsid)), WingRedis.NsKey(rp.key(sid))); err != nil {
+			return nil, err
+		}
+	}
+	return rp.SessionRead(sid)
+}
+
+// SessionDestroy delete redis session by sid.
+func (rp *redisSessionProvider) SessionDestroy(sid string) error {
+	WingRedis.Delete(rp.key(sid))
+	return nil
+}
+
+// SessionGC do nothing, the redis sessions expired by redis server.
+func (rp *redisSessionProvider) SessionGC() {}
+
+// SessionAll return 0, not support count all sessions.
+func (rp *redisSessionProvider) SessionAll() int {
+	return 0
+}
+
+// key return the origin key of session, it will namespaced by mvc.WingRedis.
+func (rp *redisSessionProvider) key(sid string) string {
+	return rp.prefix + sid
+}
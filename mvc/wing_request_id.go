@@ -0,0 +1,93 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package mvc
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/wengoldx/wcore/logger"
+	"github.com/wengoldx/wcore/secure"
+)
+
+// HeaderRequestID the http header key of request id.
+const HeaderRequestID = "X-Request-ID"
+
+// The context data key of request id.
+const ctxRequestIDKey = "wing-request-id"
+
+// The max length of request id accept from client.
+const maxRequestIDLen = 128
+
+// UseRequestID insert filter to accept request id from X-Request-ID header,
+// or generate a new one when unexist, then set it to response header, and
+// carry it by request context for output it in logs, see WingController.Logger().
+//
+// ---
+//
+//	// call it before beego.Run() in main.go
+//	mvc.UseRequestID()
+//
+//	// get request id in controller, and output logs with it
+//	rid := c.RequestID()
+//	c.Logger().I("Updated profile of", uuid)
+//
+//	// forward request id to grpc server
+//	ctx := wrpc.WithRequestID(context.Background(), c.RequestID())
+func UseRequestID(pattern ...string) {
+	router := "*"
+	if len(pattern) > 0 && pattern[0] != "" {
+		router = pattern[0]
+	}
+
+	// accept request id before the other filters which may response directly
+	beego.InsertFilter(router, beego.BeforeStatic, RequestIDFilter, false)
+}
+
+// RequestIDFilter the filter to accept or generate request id of request.
+func RequestIDFilter(ctx *context.Context) {
+	rid := ctx.Input.Header(HeaderRequestID)
+	if rid == "" || len(rid) > maxRequestIDLen || !isValidRequestID(rid) {
+		rid = secure.GenUUIDString()
+	}
+
+	ctx.Input.SetData(ctxRequestIDKey, rid)
+	ctx.Output.Header(HeaderRequestID, rid)
+	ctx.Request = ctx.Request.WithContext(logger.WithTrace(ctx.Request.Context(), rid))
+}
+
+// GetRequestID return the request id of context, or empty when unset.
+func GetRequestID(ctx *context.Context) string {
+	if rid, ok := ctx.Input.GetData(ctxRequestIDKey).(string); ok {
+		return rid
+	}
+	return ""
+}
+
+// RequestID return the request id of current request.
+func (c *WingController) RequestID() string {
+	return GetRequestID(c.Ctx)
+}
+
+// Logger return the logger to output logs with request id of current request.
+func (c *WingController) Logger() *logger.TraceLogger {
+	return logger.Trace(c.RequestID())
+}
+
+// isValidRequestID check the request id only contain visible ascii chars,
+// but not contain quotes and backslash to safe embed in json and logs.
+func isValidRequestID(rid string) bool {
+	for i := 0; i < len(rid); i++ {
+		if ch := rid[i]; ch <= ' ' || ch > '~' || ch == '"' || ch == '\\' {
+			return false
+		}
+	}
+	return true
+}
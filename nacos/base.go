@@ -11,6 +11,7 @@
 package nacos
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -104,6 +105,10 @@ func RegisterServer() *ServerStub {
 
 	logmsg := fmt.Sprintf("%s@%s:%v", app, addr, port)
 	logger.I("Registered server on", logmsg)
+	utils.RegisterHealthCheck("nacos", stub.HealthCheck)
+	utils.OnShutdown(utils.ShutdownDeregister, "nacos", func(ctx context.Context) error {
+		return stub.Deregister(app, addr, uint64(port), gp)
+	})
 	return stub
 }
 
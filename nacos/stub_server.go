@@ -11,6 +11,7 @@
 package nacos
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/astaxie/beego"
@@ -131,6 +132,19 @@ func (s *ServerStub) Deregister(name, host string, port uint64, opts ...string)
 	return nil
 }
 
+// Check nacos naming server connectivity by query services of group
+//	@params ctx context.Context unused, the health check runner control timeout
+//	@return - error handle exception
+func (s *ServerStub) HealthCheck(ctx context.Context) error {
+	if s.Stub == nil {
+		return invar.ErrInvalidClient
+	}
+
+	param := vo.GetAllServiceInfoParam{NameSpace: s.Namespace, GroupName: GP_WENGOLD, PageNo: 1, PageSize: 1}
+	_, err := s.Stub.GetAllServicesInfo(param)
+	return err
+}
+
 // Get business server registry informations from nacos remote server
 //	@params name string   business server name
 //	@params opts []string 0:group name, 1~n:clusters name of business server
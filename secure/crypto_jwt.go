@@ -61,6 +61,24 @@ func ViaJwtToken(signedToken, salt string) (string, error) {
 	return "", err
 }
 
+// Verify the encoded jwt token witch salt string, and return the whole claims,
+// it only accept HMAC signing methods.
+func ParseJwtClaims(signedToken, salt string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(signedToken, &Claims{}, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, invar.ErrInvalidToken
+		}
+		return []byte(salt), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, invar.ErrInvalidToken
+}
+
 // Encode account uuid and optianl datas as claims content of jwt token
 func EncClaims(uuid string, params ...string) string {
 	sets := []string{uuid}
@@ -0,0 +1,256 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/wengoldx/wcore/invar"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// CorsPolicy the cross domain access policy of http server, the origins
+// support wildcard subdomains as 'https://*.wengold.net', and empty or '*'
+// origins allow all domains.
+type CorsPolicy struct {
+	Origins     []string     `json:"origins"`     // allowed origins, see invar.MatchDomain()
+	Methods     []string     `json:"methods"`     // allowed methods for preflight
+	Headers     []string     `json:"headers"`     // allowed request headers, '*' allow all
+	Exposes     []string     `json:"exposes"`     // exposed response headers
+	MaxAge      int          `json:"maxage"`      // preflight cache duration in seconds
+	Credentials bool         `json:"credentials"` // whether allow cookies and auth headers
+	Routes      []*CorsRoute `json:"routes"`      // per-route overrides, the first matched used
+}
+
+// CorsRoute the cors policy of router pattern, the empty fields inherit from
+// global policy except credentials, and the pattern match by MatchRoute().
+type CorsRoute struct {
+	Pattern string `json:"pattern"`
+	CorsPolicy
+}
+
+// Default cors methods, headers and exposed headers.
+var (
+	corsDefMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsDefHeaders = []string{
+		"Origin", "Accept", "Content-Type", "Authorization", "Authoration", "Token", "Location",
+		"X-Request-ID", "If-None-Match", "If-Modified-Since",
+		HeaderSignAccessKey, HeaderSignTimestamp, HeaderSignNonce, HeaderSignature,
+	}
+	corsDefExposes = []string{"Content-Length", "Content-Type", "ETag", "X-Request-ID"}
+)
+
+// corsPolicy the current cors policy, it replaced when hot reload.
+var corsPolicy atomic.Pointer[CorsPolicy]
+
+// SetCorsPolicy set the cors policy of http server, it can be call at any time
+// to reload policy, and the unset methods, headers, exposes use default values.
+func SetCorsPolicy(policy *CorsPolicy) {
+	if policy == nil {
+		policy = &CorsPolicy{}
+	}
+
+	p := *policy
+	p.Methods = corsUpper(corsDefault(p.Methods, corsDefMethods))
+	p.Headers = corsDefault(p.Headers, corsDefHeaders)
+	p.Exposes = corsDefault(p.Exposes, corsDefExposes)
+	p.Routes = make([]*CorsRoute, 0, len(policy.Routes))
+	for _, route := range policy.Routes {
+		if route == nil || route.Pattern == "" {
+			continue
+		}
+
+		r := *route
+		r.Routes = nil
+		r.Methods = corsUpper(corsDefault(r.Methods, p.Methods))
+		r.Headers = corsDefault(r.Headers, p.Headers)
+		r.Exposes = corsDefault(r.Exposes, p.Exposes)
+		if len(r.Origins) == 0 {
+			r.Origins = p.Origins
+		}
+		if r.MaxAge == 0 {
+			r.MaxAge = p.MaxAge
+		}
+		p.Routes = append(p.Routes, &r)
+	}
+
+	corsPolicy.Store(&p)
+}
+
+// LoadCorsPolicy load cors policy from json data, such as the nacos config:
+//
+//	{
+//		"origins": ["https://*.wengold.net", "https://wengold.net"],
+//		"methods": ["GET", "POST", "PUT", "DELETE", "OPTIONS"],
+//		"exposes": ["Content-Length", "ETag", "X-Request-ID"],
+//		"maxage": 600, "credentials": true,
+//		"routes": [
+//			{"pattern": "/v3/open/**", "origins": ["*"]}
+//		]
+//	}
+func LoadCorsPolicy(data string) error {
+	policy := &CorsPolicy{}
+	if err := json.Unmarshal([]byte(data), policy); err != nil {
+		return err
+	}
+
+	SetCorsPolicy(policy)
+	logger.I("Loaded cors policy of", len(policy.Origins), "origins")
+	return nil
+}
+
+// OnCorsChanged reload cors policy when nacos config changed.
+//
+// ---
+//
+//	mc := nacos.GenMetaConfig()
+//	mc.ListenConfig("cors.json", utils.OnCorsChanged)
+func OnCorsChanged(dataId, data string) {
+	if err := LoadCorsPolicy(data); err != nil {
+		logger.E("Reload cors policy", dataId, "err:", err)
+	}
+}
+
+// ----------------
+
+// Load cors policy from app.conf file when not set, the config as:
+//
+//	[cors]
+//	origins = "https://*.wengold.net;https://wengold.net"
+//	methods = "GET;POST;PUT;DELETE;OPTIONS"
+//	headers = "Origin;Content-Type;Authorization"
+//	exposes = "Content-Length;ETag"
+//	maxage = 600
+//	credentials = true
+func loadCorsConfigs(allowCredentials bool) {
+	if corsPolicy.Load() != nil {
+		return // set by code or nacos
+	}
+
+	SetCorsPolicy(&CorsPolicy{
+		Origins:     beego.AppConfig.Strings("cors::origins"),
+		Methods:     beego.AppConfig.Strings("cors::methods"),
+		Headers:     beego.AppConfig.Strings("cors::headers"),
+		Exposes:     beego.AppConfig.Strings("cors::exposes"),
+		MaxAge:      beego.AppConfig.DefaultInt("cors::maxage", 0),
+		Credentials: beego.AppConfig.DefaultBool("cors::credentials", allowCredentials),
+	})
+}
+
+// Filter to set cors headers by current policy, it response preflight requests
+// directly, and response 403 when origin not allowed.
+func corsFilter(ctx *context.Context) {
+	origin := ctx.Input.Header("Origin")
+	policy := corsPolicy.Load()
+	if origin == "" || policy == nil {
+		return
+	}
+
+	// use the first matched route policy, or the global policy
+	url := ctx.Input.URL()
+	for _, route := range policy.Routes {
+		if MatchRoute(route.Pattern, url) {
+			policy = &route.CorsPolicy
+			break
+		}
+	}
+
+	allowed := corsAllowed(policy.Origins, origin)
+
+	preflight := ctx.Input.Method() == http.MethodOptions &&
+		ctx.Input.Header("Access-Control-Request-Method") != ""
+	if !allowed {
+		if preflight {
+			ctx.ResponseWriter.WriteHeader(http.StatusForbidden)
+		}
+		return
+	}
+
+	out := ctx.Output
+	out.Header("Vary", "Origin")
+	if !policy.Credentials && len(corsOrigins(policy.Origins)) == 0 {
+		out.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		out.Header("Access-Control-Allow-Origin", origin)
+	}
+	if policy.Credentials {
+		out.Header("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if len(policy.Exposes) > 0 {
+			out.Header("Access-Control-Expose-Headers", strings.Join(policy.Exposes, ", "))
+		}
+		return
+	}
+
+	headers := strings.Join(policy.Headers, ", ")
+	if headers == "*" {
+		headers = ctx.Input.Header("Access-Control-Request-Headers")
+	}
+	out.Header("Access-Control-Allow-Methods", strings.Join(policy.Methods, ", "))
+	if headers != "" {
+		out.Header("Access-Control-Allow-Headers", headers)
+	}
+	if policy.MaxAge > 0 {
+		out.Header("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+	}
+	ctx.ResponseWriter.WriteHeader(http.StatusNoContent)
+}
+
+// Check whether origin allowed by policy origins.
+func corsAllowed(origins []string, origin string) bool {
+	if origins = corsOrigins(origins); len(origins) == 0 {
+		return true
+	}
+	for _, domain := range origins {
+		if invar.MatchDomain(domain, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the valid origins, or empty when allow all origins.
+func corsOrigins(origins []string) []string {
+	valids := []string{}
+	for _, origin := range origins {
+		if origin = strings.TrimSpace(origin); origin == "*" {
+			return []string{}
+		} else if origin != "" {
+			valids = append(valids, origin)
+		}
+	}
+	return valids
+}
+
+// Return values, or the default values when empty.
+func corsDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
+// Return the upper case values.
+func corsUpper(values []string) []string {
+	uppers := make([]string, 0, len(values))
+	for _, value := range values {
+		uppers = append(uppers, strings.ToUpper(strings.TrimSpace(value)))
+	}
+	return uppers
+}
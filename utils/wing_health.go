@@ -0,0 +1,186 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// HealthCheck check the health of one component, it return error when unhealthy.
+type HealthCheck func(ctx context.Context) error
+
+// HealthResult the check result of one component.
+type HealthResult struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// HealthReport the aggregated check results of all components.
+type HealthReport struct {
+	Status string          `json:"status"`
+	Uptime string          `json:"uptime"`
+	Checks []*HealthResult `json:"checks"`
+}
+
+// Status of health check results.
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+var (
+	// HealthTimeout the timeout of each component check.
+	HealthTimeout = 3 * time.Second
+
+	healthLock   sync.RWMutex
+	healthChecks = make(map[string]HealthCheck)
+	healthStart  = time.Now()
+)
+
+// RegisterHealthCheck register or replace the health check of component, the
+// opened mysql, mssql, redis, mqtt, nacos and grpc clients registered auto.
+//
+// ---
+//
+//	utils.RegisterHealthCheck("oss", func(ctx context.Context) error {
+//		return service.PingOSS(ctx)
+//	})
+func RegisterHealthCheck(name string, check HealthCheck) {
+	healthLock.Lock()
+	defer healthLock.Unlock()
+	healthChecks[name] = check
+}
+
+// RemoveHealthCheck remove the health check of component.
+func RemoveHealthCheck(name string) {
+	healthLock.Lock()
+	defer healthLock.Unlock()
+	delete(healthChecks, name)
+}
+
+// CheckHealth run all health checks concurrently and return the report,
+// the report status is down when any component check failed or timeout.
+func CheckHealth(ctx context.Context) *HealthReport {
+	healthLock.RLock()
+	checks := make(map[string]HealthCheck, len(healthChecks))
+	for name, check := range healthChecks {
+		checks[name] = check
+	}
+	healthLock.RUnlock()
+
+	report := &HealthReport{
+		Status: HealthUp, Uptime: time.Since(healthStart).Truncate(time.Second).String(),
+		Checks: make([]*HealthResult, 0, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			rst := runHealthCheck(ctx, name, check)
+
+			lock.Lock()
+			defer lock.Unlock()
+			report.Checks = append(report.Checks, rst)
+			if rst.Status != HealthUp {
+				report.Status = HealthDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+	return report
+}
+
+// UseHealthChecks register /healthz and /readyz routers to output health report
+// as json, the /healthz always response 200 while server alive, but /readyz
+// response 503 when any component unhealthy, you can set custom routers by
+// the given params.
+//
+// ---
+//
+//	// call it before utils.HttpServer()
+//	utils.UseHealthChecks()
+//	utils.HttpServer()
+func UseHealthChecks(routes ...string) {
+	healthz, readyz := "/healthz", "/readyz"
+	if len(routes) > 0 && routes[0] != "" {
+		healthz = routes[0]
+	}
+	if len(routes) > 1 && routes[1] != "" {
+		readyz = routes[1]
+	}
+
+	beego.Handler(healthz, healthHandler(false))
+	beego.Handler(readyz, healthHandler(true))
+	logger.I("Expose health checks on:", healthz, readyz)
+}
+
+// Return health report handler, it response 503 for unhealthy when ready is true.
+func healthHandler(ready bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, status := CheckHealth(r.Context()), http.StatusOK
+		if ready && (report.Status != HealthUp || IsShuttingDown()) {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// Run health check with timeout and recover panic.
+func runHealthCheck(ctx context.Context, name string, check HealthCheck) *HealthResult {
+	ctx, cancel := context.WithTimeout(ctx, HealthTimeout)
+	defer cancel()
+
+	start, done := time.Now(), make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	rst := &HealthResult{Name: name, Status: HealthUp}
+	rst.Latency = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		rst.Status, rst.Error = HealthDown, err.Error()
+	}
+	return rst
+}
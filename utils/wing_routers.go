@@ -13,6 +13,7 @@ package utils
 import (
 	"encoding/json"
 	"os"
+	"path"
 	"reflect"
 	"strings"
 
@@ -151,6 +152,40 @@ func UpdateChineses(data string, descs []*SvrDesc) (string, error) {
 	return string(swagger), nil
 }
 
+// MatchRoute check the url path whether match router pattern, the pattern
+// support wildcards as:
+//
+//   - '*' or ':param' match one path segment
+//   - '**' match any remaining path segments
+//   - the other segment match by path.Match(), such as 'get*'
+//   - only '*' pattern match any url path
+func MatchRoute(pattern, url string) bool {
+	if pattern == "*" || pattern == "**" {
+		return true
+	}
+
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		url = url[:i]
+	}
+
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	us := strings.Split(strings.Trim(url, "/"), "/")
+	for i, p := range ps {
+		if p == "**" {
+			return true
+		} else if i >= len(us) {
+			return false
+		}
+
+		if p == "*" || strings.HasPrefix(p, ":") {
+			continue
+		} else if matched, _ := path.Match(p, us[i]); !matched {
+			return false
+		}
+	}
+	return len(ps) == len(us)
+}
+
 // --------------------------------------------
 
 // Load local server routers from swagger.json file.
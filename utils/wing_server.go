@@ -12,13 +12,13 @@ package utils
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/astaxie/beego"
 	"github.com/astaxie/beego/logs"
-	"github.com/astaxie/beego/plugins/cors"
 	"github.com/wengoldx/wcore/logger"
 )
 
@@ -26,10 +26,26 @@ import (
 // For setup HTTP Server
 // ===========================
 
+// middlewares the http middlewares to wrap beego handlers, see UseMiddleWares().
+var middlewares []beego.MiddleWare
+
+// UseMiddleWares append http middlewares to wrap beego handlers, the first one
+// will be the outermost handler, it must call before HttpServer().
+func UseMiddleWares(mws ...beego.MiddleWare) {
+	middlewares = append(middlewares, mws...)
+}
+
 // Start and excute http server base on beego, by default, it just
 // support restful interface not socket.io connection, but you can
 // set allowCredentials as true to on socket.io conect function.
 //
+// The cross domain access policy load from [cors] section of app.conf file,
+// or set by SetCorsPolicy(), LoadCorsPolicy() before, and it can be hot
+// reload from nacos config by OnCorsChanged().
+//
+// The server shutdown gracefully on SIGTERM or SIGINT signal, it stop accepting
+// and drain in-flight requests, then execute shutdown hooks, see OnShutdown().
+//
 // `USAGE` :
 //
 //	// use for restful interface server
@@ -42,20 +58,23 @@ import (
 //	}
 func HttpServer(allowCredentials ...bool) {
 	ignoreSysSignalPIPE()
-	if len(allowCredentials) > 0 && allowCredentials[0] {
-		accessAllowOriginBy(beego.BeforeRouter, "*", allowCredentials[0])
-		accessAllowOriginBy(beego.BeforeStatic, "*", allowCredentials[0])
-	} else {
-		accessAllowOriginBy(beego.BeforeRouter, "*", false)
-		accessAllowOriginBy(beego.BeforeStatic, "*", false)
-	}
+	loadCorsConfigs(len(allowCredentials) > 0 && allowCredentials[0])
+	beego.InsertFilter("*", beego.BeforeRouter, corsFilter)
+	beego.InsertFilter("*", beego.BeforeStatic, corsFilter)
 
 	// just output log to file on prod mode
 	if beego.BConfig.RunMode != "dev" &&
 		logger.GetLevel() != logger.LevelDebug {
 		beego.BeeLogger.DelLogger(logs.AdapterConsole)
 	}
-	beego.Run()
+
+	// run http server until shutdown gracefully
+	done := make(chan struct{})
+	go func() {
+		beego.RunWithMiddleWares("", wrapMiddleWares)
+		close(done)
+	}()
+	waitShutdown(done)
 }
 
 // Start and excute both restful and socket.io server
@@ -63,8 +82,9 @@ func SocketServer() {
 	HttpServer(true)
 }
 
-// Allow cross domain access for localhost,
-// the port number must config in /conf/app.conf file like :
+// Allow cross domain access for localhost, it append localhost origin into
+// cors policy, and the category param unused since cors filters inserted
+// by HttpServer(), the port number must config in /conf/app.conf file like :
 //
 // ---
 //
@@ -72,8 +92,14 @@ func SocketServer() {
 //	httpport=3200
 func AccessAllowOriginByLocal(category int, allowCredentials bool) {
 	if beego.BConfig.Listen.HTTPPort > 0 {
-		localhosturl := fmt.Sprintf("http://127.0.0.1:%v/", beego.BConfig.Listen.HTTPPort)
-		accessAllowOriginBy(category, localhosturl, allowCredentials)
+		loadCorsConfigs(allowCredentials)
+		policy := *corsPolicy.Load()
+		if len(corsOrigins(policy.Origins)) > 0 /* not allow all */ {
+			localhost := fmt.Sprintf("http://127.0.0.1:%v", beego.BConfig.Listen.HTTPPort)
+			policy.Origins = append(append([]string{}, policy.Origins...), localhost)
+		}
+		policy.Credentials = policy.Credentials || allowCredentials
+		SetCorsPolicy(&policy)
 	}
 }
 
@@ -93,14 +119,10 @@ func ignoreSysSignalPIPE() {
 	}()
 }
 
-// Allow cross domain access for the given origins
-func accessAllowOriginBy(category int, origins string, allowCredentials bool) {
-	beego.InsertFilter("*", category, cors.Allow(&cors.Options{
-		AllowAllOrigins:  !allowCredentials,
-		AllowCredentials: allowCredentials,
-		AllowOrigins:     []string{origins}, // use to set allow Origins
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type", "Authoration", "Token"},
-		ExposeHeaders:    []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type"},
-	}))
+// Wrap beego handler by middlewares, the first middleware is the outermost.
+func wrapMiddleWares(handler http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
 }
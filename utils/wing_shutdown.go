@@ -0,0 +1,156 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package utils
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/wengoldx/wcore/logger"
+)
+
+// ShutdownHook release resources when server shutdown.
+type ShutdownHook func(ctx context.Context) error
+
+// Phases of shutdown hooks, the deregister hooks execute before http server
+// closed, and the others execute by phases order after http server drained,
+// the hooks in same phase execute by registered order.
+const (
+	ShutdownDeregister = iota // deregister server from service center, such as nacos
+	ShutdownServer            // stop the other servers, such as grpc server
+	ShutdownClient            // disconnect clients, such as mqtt and grpc clients
+	ShutdownStorage           // close database and cache pools, such as mysql and redis
+)
+
+// shutdownItem the registered shutdown hook.
+type shutdownItem struct {
+	phase int
+	name  string
+	hook  ShutdownHook
+}
+
+var (
+	// ShutdownTimeout the max duration to drain in-flight requests, and the
+	// max duration of shutdown hooks, it can be set by 'shutdowntimeout'
+	// config in seconds in app.conf file.
+	ShutdownTimeout = 15 * time.Second
+
+	// ShutdownDelay the duration to wait after server deregistered and /readyz
+	// response 503, before close http listener, for the service center and load
+	// balancers stop routing requests, it can be set by 'shutdowndelay' config
+	// in seconds in app.conf file.
+	ShutdownDelay = 3 * time.Second
+
+	shutdownLock  sync.Mutex
+	shutdownHooks []*shutdownItem
+	shuttingDown  atomic.Bool
+)
+
+// OnShutdown register or replace the named shutdown hook in phase, the opened
+// nacos, grpc, mqtt, mysql, mssql and redis resources registered auto.
+//
+// ---
+//
+//	utils.OnShutdown(utils.ShutdownClient, "wsio", func(ctx context.Context) error {
+//		return wsio.Close()
+//	})
+func OnShutdown(phase int, name string, hook ShutdownHook) {
+	shutdownLock.Lock()
+	defer shutdownLock.Unlock()
+	for _, item := range shutdownHooks {
+		if item.name == name {
+			item.phase, item.hook = phase, hook
+			return
+		}
+	}
+	shutdownHooks = append(shutdownHooks, &shutdownItem{phase: phase, name: name, hook: hook})
+}
+
+// IsShuttingDown check whether server is shutting down, the /readyz router
+// response 503 during shutting down.
+func IsShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// ----------------
+
+// Wait SIGTERM or SIGINT signal, or http server exit, then shutdown http server
+// gracefully, execute shutdown hooks and flush logs.
+func waitShutdown(done <-chan struct{}) {
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGTERM, syscall.SIGINT)
+	exited := false
+	select {
+	case sig := <-sc:
+		logger.I("Received signal", sig.String()+", shutting down server...")
+	case <-done:
+		logger.W("Http server exited, shutting down server...")
+		exited = true
+	}
+
+	// the second signal will kill server directly
+	signal.Stop(sc)
+	shuttingDown.Store(true)
+	if timeout := beego.AppConfig.DefaultInt("shutdowntimeout", 0); timeout > 0 {
+		ShutdownTimeout = time.Duration(timeout) * time.Second
+	}
+	if delay := beego.AppConfig.DefaultInt("shutdowndelay", -1); delay >= 0 {
+		ShutdownDelay = time.Duration(delay) * time.Second
+	}
+
+	// deregister server and wait the clients stop routing requests
+	runShutdownHooks(ShutdownDeregister, ShutdownDeregister)
+	if !exited {
+		time.Sleep(ShutdownDelay)
+	}
+
+	// stop accepting and drain in-flight requests
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	if err := beego.BeeApp.Server.Shutdown(ctx); err != nil {
+		logger.E("Drain http requests, err:", err)
+	}
+	cancel()
+
+	runShutdownHooks(ShutdownServer, ShutdownStorage)
+	logger.I("Server", beego.BConfig.AppName, "exited")
+	logger.Flush()
+}
+
+// Execute shutdown hooks of phases in [from, to] range by phases order in timeout.
+func runShutdownHooks(from, to int) {
+	items := []*shutdownItem{}
+	shutdownLock.Lock()
+	for _, item := range shutdownHooks {
+		if item.phase >= from && item.phase <= to {
+			items = append(items, item)
+		}
+	}
+	shutdownLock.Unlock()
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].phase < items[j].phase })
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	for _, item := range items {
+		if err := item.hook(ctx); err != nil {
+			logger.E("Shutdown", item.name+", err:", err)
+		} else {
+			logger.I("Shutdown", item.name)
+		}
+	}
+}
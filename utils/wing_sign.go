@@ -0,0 +1,138 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package utils
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wengoldx/wcore/secure"
+)
+
+// Http headers of AK/SK request signature.
+const (
+	HeaderSignAccessKey = "X-Wing-AccessKey" // access key of machine client
+	HeaderSignTimestamp = "X-Wing-Timestamp" // request unix time in seconds
+	HeaderSignNonce     = "X-Wing-Nonce"     // random nonce to prevent replay
+	HeaderSignature     = "X-Wing-Signature" // base64 HmacSHA256 signature
+)
+
+// CanonicalRequest return the canonical string to sign a request, it join the
+// follow fields by '\n' :
+//
+//   - upper case http method, such as 'POST'
+//   - url escaped path, such as '/v3/acc/info'
+//   - query params sorted by key and value, such as 'a=1&b=2&b=3'
+//   - hex encoded sha256 hash of request body
+//   - timestamp in unix seconds
+//   - nonce string
+//
+// Notice that the body hash cover the decoded body when request body compressed
+// by Content-Encoding, and the server reject the signed body over its MaxMemory.
+func CanonicalRequest(method, path string, query url.Values, body []byte, timestamp, nonce string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{
+		strings.ToUpper(method), path, strings.Join(params, "&"),
+		secure.HashSHA256Hex(body), timestamp, nonce,
+	}, "\n")
+}
+
+// SignRequest sign the http request by access key and secret key, it set the
+// signature headers into request, and keep request body readable. The gzip
+// or deflate compressed body will decoded to sign, same as server verified.
+func SignRequest(req *http.Request, ak, sk string) error {
+	body := []byte{}
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body, body = io.NopCloser(bytes.NewReader(data)), data
+
+		encoding := strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding")))
+		if body, err = decodeSignBody(encoding, data); err != nil {
+			return err
+		}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := secure.GenRandUUID(16)
+	canonical := CanonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), body, timestamp, nonce)
+
+	req.Header.Set(HeaderSignAccessKey, ak)
+	req.Header.Set(HeaderSignTimestamp, timestamp)
+	req.Header.Set(HeaderSignNonce, nonce)
+	req.Header.Set(HeaderSignature, secure.SignSHA256(sk, canonical))
+	return nil
+}
+
+// SignRequestBy return the SetRequest middle-ware to sign requests by access
+// key and secret key, the server will verify it by mvc.HMACScheme.
+//
+// `USAGE` :
+//
+//	signer := utils.SignRequestBy(ak, sk)
+//	body, err := utils.HttpClientPost(tagurl, signer, postdata)
+func SignRequestBy(ak, sk string) SetRequest {
+	return func(req *http.Request) (bool, error) {
+		return false, SignRequest(req, ak, sk)
+	}
+}
+
+// ----------------
+
+// decodeSignBody return the decoded body of gzip or deflate encoding to sign.
+func decodeSignBody(encoding string, data []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch encoding {
+	case "gzip":
+		if reader, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	case "deflate":
+		if reader, err = zlib.NewReader(bytes.NewReader(data)); err != nil {
+			reader = flate.NewReader(bytes.NewReader(data))
+		}
+	default:
+		return data, nil
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -17,16 +17,20 @@ import (
 	"encoding/xml"
 	"fmt"
 	"net"
+	"sync"
 
 	"github.com/astaxie/beego"
+	"github.com/wengoldx/wcore/invar"
 	"github.com/wengoldx/wcore/logger"
 	"github.com/wengoldx/wcore/nacos"
+	"github.com/wengoldx/wcore/utils"
 	acc "github.com/wengoldx/wcore/wrpc/accservice/proto"
 	mea "github.com/wengoldx/wcore/wrpc/measure/proto"
 	webss "github.com/wengoldx/wcore/wrpc/webss/proto"
 	chat "github.com/wengoldx/wcore/wrpc/wgchat/proto"
 	pay "github.com/wengoldx/wcore/wrpc/wgpay/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 )
 
@@ -45,8 +49,13 @@ type GrpcStub struct {
 	Certs   map[string]*nacos.GrpcCert // Grpc handler certs
 	Clients map[string]any             // Grpc client handlers
 
+	// Grpc client connections, mapped by server name
+	conns     map[string]*grpc.ClientConn
+	connsLock sync.RWMutex
+
 	// Current grpc server if registried
 	isRegistried bool
+	registLock   sync.RWMutex
 
 	// Global handler function to return grpc server handler
 	SvrHandlerFunc GrpcHandlerFunc
@@ -62,6 +71,7 @@ func Singleton() *GrpcStub {
 			isRegistried: false,
 			Certs:        make(map[string]*nacos.GrpcCert),
 			Clients:      make(map[string]any),
+			conns:        make(map[string]*grpc.ClientConn),
 		}
 	}
 	return grpcStub
@@ -87,7 +97,7 @@ func (stub *GrpcStub) StartGrpcServer() {
 	if stub.SvrHandlerFunc == nil {
 		logger.E("Not setup global grpc handler!")
 		return
-	} else if stub.isRegistried {
+	} else if stub.registried() {
 		return // drop the duplicate registry
 	}
 
@@ -117,12 +127,19 @@ func (stub *GrpcStub) StartGrpcServer() {
 
 	// generate grpc server handler with TLS secure
 	cred := credentials.NewServerTLSFromCert(&cert)
-	svr := grpc.NewServer(grpc.Creds(cred))
+	svr := grpc.NewServer(grpc.Creds(cred), grpc.UnaryInterceptor(UnaryServerTrace))
 	stub.SvrHandlerFunc(svr)
 	logger.I("Running Grpc server:", svrname, "on port", port)
 
-	stub.isRegistried = true
-	defer func(stub *GrpcStub) { stub.isRegistried = false }(stub)
+	stub.setRegistried(true)
+	defer stub.setRegistried(false)
+	utils.OnShutdown(utils.ShutdownServer, "grpc-server", stopGrpcServer(svr))
+	utils.RegisterHealthCheck("grpc-server", func(ctx context.Context) error {
+		if !stub.registried() {
+			return invar.ErrUnperparedState
+		}
+		return nil
+	})
 	if err := svr.Serve(lis); err != nil {
 		logger.E("Start grpc server, err:", err)
 	}
@@ -163,7 +180,8 @@ func (stub *GrpcStub) GenClient(svrkey, addr string, port int) {
 	// generate grpc client handler with TLS secure
 	grpcsvr := fmt.Sprintf("%s:%d", addr, port)
 	cred := credentials.NewClientTLSFromCert(cp, svrkey)
-	conn, err := grpc.Dial(grpcsvr, grpc.WithTransportCredentials(cred))
+	conn, err := grpc.Dial(grpcsvr, grpc.WithTransportCredentials(cred),
+		grpc.WithUnaryInterceptor(UnaryClientTrace))
 	if err != nil {
 		logger.E("dial grpc address", grpcsvr, " fialed", err)
 		return
@@ -171,6 +189,19 @@ func (stub *GrpcStub) GenClient(svrkey, addr string, port int) {
 
 	// content grpc client by server name
 	logger.I("Grpc client:", svrkey, "connect", grpcsvr)
+	stub.connsLock.Lock()
+	old, redial := stub.conns[svrkey]
+	stub.conns[svrkey] = conn
+	if redial && old != nil {
+		old.Close() // close the connection of previous dial
+	}
+	stub.connsLock.Unlock()
+
+	// register hooks only once, they always use the current connection
+	if !redial {
+		utils.RegisterHealthCheck("grpc:"+svrkey, stub.ConnHealthCheck(svrkey))
+		utils.OnShutdown(utils.ShutdownClient, "grpc:"+svrkey, stub.closeConn(svrkey))
+	}
 	switch svrkey {
 	case SvrAcc:
 		stub.Clients[svrkey] = acc.NewAccClient(conn)
@@ -185,6 +216,79 @@ func (stub *GrpcStub) GenClient(svrkey, addr string, port int) {
 	}
 }
 
+// Return the health check of grpc client connection, it try to connect when
+// the connection idle, and wait until ready or check timeout.
+func (stub *GrpcStub) ConnHealthCheck(svrkey string) utils.HealthCheck {
+	return func(ctx context.Context) error {
+		stub.connsLock.RLock()
+		conn, ok := stub.conns[svrkey]
+		stub.connsLock.RUnlock()
+		if !ok || conn == nil {
+			return invar.ErrInvalidClient
+		}
+
+		for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+			if state == connectivity.Shutdown {
+				return invar.ErrClientOffline
+			} else if state == connectivity.Idle {
+				conn.Connect()
+			}
+
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("grpc connection %s", state)
+			}
+		}
+		return nil
+	}
+}
+
+// Return the shutdown hook to stop grpc server gracefully, it force stop
+// the server when pending rpcs not finished in timeout.
+func stopGrpcServer(svr *grpc.Server) utils.ShutdownHook {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			svr.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			svr.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Return the shutdown hook to close current grpc client connection.
+func (stub *GrpcStub) closeConn(svrkey string) utils.ShutdownHook {
+	return func(ctx context.Context) error {
+		stub.connsLock.RLock()
+		conn := stub.conns[svrkey]
+		stub.connsLock.RUnlock()
+		if conn == nil {
+			return nil
+		}
+		return conn.Close()
+	}
+}
+
+// Return whether local grpc server registried.
+func (stub *GrpcStub) registried() bool {
+	stub.registLock.RLock()
+	defer stub.registLock.RUnlock()
+	return stub.isRegistried
+}
+
+// Set the registried state of local grpc server.
+func (stub *GrpcStub) setRegistried(registried bool) {
+	stub.registLock.Lock()
+	stub.isRegistried = registried
+	stub.registLock.Unlock()
+}
+
 // Parse all grpc certs from nacos config data, and cache to certs map
 func (stub *GrpcStub) ParseCerts(data string) error {
 	certs := nacos.GrpcCerts{}
@@ -250,16 +354,17 @@ func (stub *GrpcStub) Pay() pay.WgpayClient {
 // Account Authentications Request
 // ------------------------------------------------------------
 
-// Auth header token and return account uuid and password
-func (stub *GrpcStub) AuthHeaderToken(token string) (string, string) {
+// Auth header token and return account uuid and password, the request id
+// carried by ctx will forward to account service, see logger.WithTrace().
+func (stub *GrpcStub) AuthHeaderToken(ctx context.Context, token string) (string, string) {
 	if accGRPC := stub.Acc(); accGRPC == nil {
 		logger.E("Acc RPC instance not inited!")
 		return "", ""
 	} else {
 		param := &acc.Token{Token: token}
-		resp, err := accGRPC.ViaToken(context.Background(), param)
+		resp, err := accGRPC.ViaToken(ctx, param)
 		if err != nil {
-			logger.E("RPC auth token, err:", err)
+			logger.Trace(logger.TraceOf(ctx)).E("RPC auth token, err:", err)
 			return "", ""
 		}
 
@@ -267,16 +372,17 @@ func (stub *GrpcStub) AuthHeaderToken(token string) (string, string) {
 	}
 }
 
-// Auth account role from http header
-func (stub *GrpcStub) AuthHeaderRole(uuid, url, method string) bool {
+// Auth account role from http header, the request id carried by ctx will
+// forward to account service, see logger.WithTrace().
+func (stub *GrpcStub) AuthHeaderRole(ctx context.Context, uuid, url, method string) bool {
 	if accGRPC := stub.Acc(); accGRPC == nil {
 		logger.E("Acc RPC instance not inited!")
 		return false
 	} else {
 		param := &acc.Role{Uuid: uuid, Router: url, Method: method}
-		resp, err := accGRPC.ViaRole(context.Background(), param)
+		resp, err := accGRPC.ViaRole(ctx, param)
 		if err != nil {
-			logger.E("RPC auth", uuid, "role, err:", err)
+			logger.Trace(logger.TraceOf(ctx)).E("RPC auth", uuid, "role, err:", err)
 			return false
 		}
 
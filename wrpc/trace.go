@@ -0,0 +1,76 @@
+// Copyright (c) 2018-Now Dunyu All Rights Reserved.
+//
+// Author      : https://www.wengold.net
+// Email       : support@wengold.net
+//
+// Prismy.No | Date       | Modified by. | Description
+// -------------------------------------------------------------------
+// 00001       2026/10/18   yangping       New version
+// -------------------------------------------------------------------
+
+package wrpc
+
+import (
+	"context"
+
+	"github.com/wengoldx/wcore/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetaRequestID the grpc metadata key of request id, same as http X-Request-ID header.
+const MetaRequestID = "x-request-id"
+
+// WithRequestID return a context which forward the request id to grpc server.
+//
+// `USAGE` :
+//
+//	ctx := wrpc.WithRequestID(context.Background(), c.RequestID())
+//	resp, err := wrpc.Singleton().Acc().ViaToken(ctx, param)
+//
+//	// or use the request context which carry request id
+//	resp, err := wrpc.Singleton().Acc().ViaToken(c.Ctx.Request.Context(), param)
+func WithRequestID(ctx context.Context, rid string) context.Context {
+	if rid == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetaRequestID, rid)
+}
+
+// RequestID return the request id from incoming grpc metadata, or carried by
+// context, it used in grpc server handlers.
+//
+// ---
+//
+//	logger.Trace(wrpc.RequestID(ctx)).I("Handle grpc request")
+func RequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if rids := md.Get(MetaRequestID); len(rids) > 0 {
+			return rids[0]
+		}
+	}
+	return logger.TraceOf(ctx)
+}
+
+// UnaryClientTrace the grpc client interceptor to forward the request id which
+// carried by context when outgoing metadata not contain request id.
+func UnaryClientTrace(ctx context.Context, method string, req, reply any,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if len(md.Get(MetaRequestID)) == 0 {
+		ctx = WithRequestID(ctx, logger.TraceOf(ctx))
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// UnaryServerTrace the grpc server interceptor to extract request id from incoming
+// metadata, and carry it by handler context, see logger.TraceOf().
+func UnaryServerTrace(ctx context.Context, req any,
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if rids := md.Get(MetaRequestID); len(rids) > 0 {
+			ctx = logger.WithTrace(ctx, rids[0])
+		}
+	}
+	return handler(ctx, req)
+}